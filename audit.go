@@ -0,0 +1,325 @@
+package minienv
+
+import (
+	"context"
+	"encoding"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Source describes where the value of a field was taken from.
+type Source string
+
+const (
+	// The value was forced with WithOverrides
+	SourceOverride Source = "override"
+
+	// The value was read from the environment
+	SourceEnv Source = "env"
+
+	// The value was returned by a provider supplied with WithProvider
+	SourceProvider Source = "provider"
+
+	// The value was read from an env-file supplied with WithFile
+	SourceFile Source = "file"
+
+	// The value was supplied with WithFallbackValues
+	SourceFallback Source = "fallback"
+
+	// The value was decoded from the JSON blob supplied with WithJSONBlob
+	SourceJSONBlob Source = "json"
+
+	// The value is the default specified in the tag
+	SourceDefault Source = "default"
+
+	// No value was found and the field is optional
+	SourceUnset Source = "unset"
+)
+
+// The value that is reported instead of the actual value of a sensitive field
+const redacted = "***"
+
+// Replaces every sensitive value with the same placeholder, used if no WithMaskFunc was supplied
+func redact(string) string {
+	return redacted
+}
+
+// Supply a function that masks the values of sensitive fields wherever they are reported,
+// for example to keep the last characters visible. By default every value is replaced by "***".
+func WithMaskFunc(mask func(value string) string) Option {
+	return func(c *LoadConfig) error {
+		if mask == nil {
+			return errors.New("mask function must not be nil")
+		}
+
+		c.mask = mask
+		return nil
+	}
+}
+
+// AuditEntry records how a single field was loaded.
+type AuditEntry struct {
+	// The name of the struct field
+	Field string
+
+	// The key that was used for the lookup, including any prefix
+	Key string
+
+	// Where the value was taken from
+	Source Source
+
+	// The raw value that was loaded, redacted if the field is sensitive
+	Value string
+
+	// Whether the field was marked as sensitive
+	Sensitive bool
+}
+
+// Load variables into the provided struct exactly like Load does,
+// but additionally return a record of where each field got its value from.
+//
+// Values of fields that are tagged as `sensitive` are redacted in the returned entries,
+// see WithMaskFunc to customize how.
+func LoadWithAudit(obj interface{}, options ...Option) ([]AuditEntry, error) {
+	config, err := load(context.Background(), obj, options...)
+	if err != nil {
+		return nil, err
+	}
+
+	return config.audit, nil
+}
+
+// Write the effective configuration to the writer after a successful load.
+// Every loaded field is written as a KEY=value line in the format of an env-file with the final value of the field,
+// values of sensitive fields are masked just like in the audit.
+func WithEmitEffective(w io.Writer) Option {
+	return func(c *LoadConfig) error {
+		c.emit = w
+		return nil
+	}
+}
+
+// Leave out fields at their zero value when writing the effective configuration with WithEmitEffective,
+// like zero numbers, empty strings, false and nil or empty slices and maps, which keeps the output minimal.
+// A zero value that replaces a non-zero default is still written, since leaving it out would load the default.
+// Skipped fields that have a default in their tag are written as a comment with the default instead.
+func WithSkipZeroValues() Option {
+	return func(c *LoadConfig) error {
+		c.skipZero = true
+		return nil
+	}
+}
+
+// A field whose value is written by WithEmitEffective
+type emittedField struct {
+	key   string
+	field reflect.Value
+	tag   tag
+
+	// the value the field was loaded from, only set if it was read from a source or its default
+	raw    string
+	hasRaw bool
+}
+
+// Writes the values of the fields as KEY=value lines that load the same values again.
+// Fields that were read from a source are written with the value that was read,
+// all others with their final value encoded the way their tag expects it.
+func emitEffective(w io.Writer, config *LoadConfig) error {
+	for _, f := range config.emitted {
+		prefix, val := "", f.raw
+		if !f.hasRaw {
+			val = encodeValue(f.field, f.tag, config)
+		}
+
+		// zero values are left out if they load the same value without being written,
+		// a default is written as a comment instead
+		if config.skipZero && isZeroValue(f.field) && defaultIsZero(f, config) {
+			if f.tag.defaultValue == "" {
+				continue
+			}
+
+			prefix, val = "# ", f.tag.defaultValue
+		}
+
+		if f.tag.sensitive {
+			val = config.mask(val)
+		}
+
+		_, err := fmt.Fprintf(w, "%s%s=%s\n", prefix, f.key, val)
+		if err != nil {
+			return fmt.Errorf("failed to emit effective configuration: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// Checks whether the field has no default or one that sets it to its zero value.
+func defaultIsZero(f emittedField, config *LoadConfig) bool {
+	if f.tag.defaultValue == "" {
+		return true
+	}
+
+	val, err := resolveDefault(f.tag.defaultValue)
+	if err != nil {
+		return false
+	}
+
+	def := reflect.New(f.field.Type()).Elem()
+	err = setField(def, val, f.tag, config)
+	return err == nil && isZeroValue(def)
+}
+
+// Checks if the value is its zero value or an empty slice or map
+func isZeroValue(f reflect.Value) bool {
+	if f.Kind() == reflect.Slice || f.Kind() == reflect.Map {
+		return f.Len() == 0
+	}
+
+	return f.IsZero()
+}
+
+// Encodes the final value of a field so that loading it with the tag results in the same value,
+// by undoing the options of the tag that change the value while it is set, like negate or base64.
+func encodeValue(f reflect.Value, t tag, config *LoadConfig) string {
+	if f.Kind() == reflect.Ptr {
+		if f.IsNil() {
+			return ""
+		}
+
+		return encodeValue(f.Elem(), t, config)
+	}
+
+	switch {
+	case t.negate:
+		return strconv.FormatBool(!f.Bool())
+
+	case t.count != "":
+		return strings.Repeat(t.count, int(f.Int()))
+
+	case t.conversion != 0:
+		return strconv.FormatFloat(numberOf(f)/t.conversion, 'g', -1, 64)
+
+	case t.bitflags:
+		return encodeBitFlags(int(f.Int()), t, config)
+
+	case t.base64:
+		return base64.StdEncoding.EncodeToString([]byte(formatValue(f, t)))
+	}
+
+	return formatValue(f, t)
+}
+
+// Returns the names of all flags whose bits are set in the mask, sorted by name.
+func encodeBitFlags(mask int, t tag, config *LoadConfig) string {
+	var names []string
+	for name, bit := range config.bitFlags {
+		if bit != 0 && mask&bit == bit {
+			names = append(names, name)
+		}
+	}
+
+	slices.Sort(names)
+	return strings.Join(names, t.delimiter)
+}
+
+// Returns the value of a number field as a float
+func numberOf(f reflect.Value) float64 {
+	switch {
+	case f.CanInt():
+		return float64(f.Int())
+	case f.CanUint():
+		return float64(f.Uint())
+	default:
+		return f.Float()
+	}
+}
+
+// Formats the value of a field the way it would be written in the environment,
+// slices and maps are joined with the delimiter and separator of the tag.
+func formatValue(f reflect.Value, t tag) string {
+	if f.Kind() == reflect.Ptr {
+		if f.IsNil() {
+			return ""
+		}
+
+		return formatValue(f.Elem(), t)
+	}
+
+	if t.json {
+		b, err := json.Marshal(f.Interface())
+		if err == nil {
+			return string(b)
+		}
+	}
+
+	// file modes are read as octal numbers, but their String method writes them like -rw-r--r--
+	if f.Type() == fileModeType {
+		return fmt.Sprintf("%#o", f.Uint())
+	}
+
+	// types like time.Time or net.IP know best how to write themselves
+	v := f
+	if f.CanAddr() {
+		v = f.Addr()
+	}
+
+	if marshaler, ok := v.Interface().(encoding.TextMarshaler); ok {
+		b, err := marshaler.MarshalText()
+		if err == nil {
+			return string(b)
+		}
+	}
+
+	if stringer, ok := v.Interface().(fmt.Stringer); ok {
+		return stringer.String()
+	}
+
+	switch f.Kind() {
+	case reflect.Slice, reflect.Array:
+		if f.Kind() == reflect.Slice && f.Type().Elem().Kind() == reflect.Uint8 {
+			return string(f.Bytes())
+		}
+
+		parts := make([]string, f.Len())
+		for i := range parts {
+			parts[i] = formatValue(f.Index(i), t)
+		}
+
+		return strings.Join(parts, t.delimiter)
+
+	case reflect.Map:
+		entries := make([]string, 0, f.Len())
+		iter := f.MapRange()
+		for iter.Next() {
+			entries = append(entries, formatValue(iter.Key(), t)+t.kvSeparator+formatValue(iter.Value(), t))
+		}
+
+		// maps are unordered, so the entries are sorted to keep the output stable
+		slices.Sort(entries)
+		return strings.Join(entries, t.delimiter)
+	}
+
+	return fmt.Sprint(f.Interface())
+}
+
+func newAuditEntry(field string, key string, source Source, val string, t tag, mask func(string) string) AuditEntry {
+	if t.sensitive {
+		val = mask(val)
+	}
+
+	return AuditEntry{
+		Field:     field,
+		Key:       key,
+		Source:    source,
+		Value:     val,
+		Sensitive: t.sensitive,
+	}
+}
@@ -0,0 +1,398 @@
+package minienv_test
+
+import (
+	"bytes"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/yannickalex07/minienv"
+)
+
+func TestLoadWithAudit(t *testing.T) {
+	// Arrange
+	type S struct {
+		FromEnv      string `env:"FROM_ENV"`
+		FromFile     string `env:"FROM_FILE"`
+		FromFallback string `env:"FROM_FALLBACK"`
+		FromDefault  string `env:"FROM_DEFAULT,default=def"`
+		Unset        string `env:"UNSET,optional"`
+		Secret       string `env:"SECRET,sensitive"`
+	}
+
+	os.Setenv("FROM_ENV", "env")
+	defer os.Unsetenv("FROM_ENV")
+
+	os.Setenv("SECRET", "hunter2")
+	defer os.Unsetenv("SECRET")
+
+	filename := "test.env"
+
+	CreateFile(t, filename, []string{
+		"FROM_FILE=file",
+	})
+	defer RemoveFile(t, filename)
+
+	values := map[string]string{
+		"FROM_FALLBACK": "fallback",
+	}
+
+	// Act
+	var s S
+	entries, err := minienv.LoadWithAudit(&s, minienv.WithFile(true, filename), minienv.WithFallbackValues(values))
+
+	// Assert
+	assert.Nil(t, err)
+	assert.Equal(t, "hunter2", s.Secret)
+	assert.Equal(t, []minienv.AuditEntry{
+		{Field: "FromEnv", Key: "FROM_ENV", Source: minienv.SourceEnv, Value: "env"},
+		{Field: "FromFile", Key: "FROM_FILE", Source: minienv.SourceFile, Value: "file"},
+		{Field: "FromFallback", Key: "FROM_FALLBACK", Source: minienv.SourceFallback, Value: "fallback"},
+		{Field: "FromDefault", Key: "FROM_DEFAULT", Source: minienv.SourceDefault, Value: "def"},
+		{Field: "Unset", Key: "UNSET", Source: minienv.SourceUnset, Value: ""},
+		{Field: "Secret", Key: "SECRET", Source: minienv.SourceEnv, Value: "***", Sensitive: true},
+	}, entries)
+}
+
+func TestLoadWithAuditAndPrefix(t *testing.T) {
+	// Arrange
+	type S struct {
+		Value string `env:"VALUE"`
+	}
+
+	os.Setenv("APP_VALUE", "val")
+	defer os.Unsetenv("APP_VALUE")
+
+	// Act
+	var s S
+	entries, err := minienv.LoadWithAudit(&s, minienv.WithPrefix("APP_"))
+
+	// Assert
+	assert.Nil(t, err)
+	assert.Len(t, entries, 1)
+	assert.Equal(t, "APP_VALUE", entries[0].Key)
+}
+
+func TestLoadWithAuditAndError(t *testing.T) {
+	// Arrange
+	type S struct {
+		Value string `env:"VALUE"`
+	}
+
+	// Act
+	var s S
+	entries, err := minienv.LoadWithAudit(&s)
+
+	// Assert
+	assert.Error(t, err)
+	assert.Nil(t, entries)
+}
+
+func TestLoadWithAuditAndMaskFunc(t *testing.T) {
+	// Arrange
+	type S struct {
+		Token string `env:"TOKEN,sensitive"`
+		Short string `env:"SHORT,sensitive"`
+		User  string `env:"USER_NAME"`
+	}
+
+	os.Setenv("TOKEN", "abcdefgh1234")
+	defer os.Unsetenv("TOKEN")
+
+	os.Setenv("SHORT", "abc")
+	defer os.Unsetenv("SHORT")
+
+	os.Setenv("USER_NAME", "admin")
+	defer os.Unsetenv("USER_NAME")
+
+	lastFour := func(value string) string {
+		if len(value) <= 4 {
+			return "***"
+		}
+
+		return "***" + value[len(value)-4:]
+	}
+
+	// Act
+	var s S
+	entries, err := minienv.LoadWithAudit(&s, minienv.WithMaskFunc(lastFour))
+
+	// Assert
+	assert.Nil(t, err)
+	assert.Equal(t, "abcdefgh1234", s.Token)
+	assert.Equal(t, "***1234", entries[0].Value)
+	assert.Equal(t, "***", entries[1].Value)
+	assert.Equal(t, "admin", entries[2].Value)
+}
+
+func TestLoadWithAuditAndNilMaskFunc(t *testing.T) {
+	// Arrange
+	type S struct {
+		Token string `env:"TOKEN,optional,sensitive"`
+	}
+
+	// Act
+	var s S
+	_, err := minienv.LoadWithAudit(&s, minienv.WithMaskFunc(nil))
+
+	// Assert
+	assert.Error(t, err)
+	assert.ErrorContains(t, err, "mask function must not be nil")
+}
+
+func TestLoadWithSensitiveValueInError(t *testing.T) {
+	// Arrange
+	type Number struct {
+		Value int `env:"SECRET_VALUE,sensitive"`
+	}
+
+	type OneOf struct {
+		Value string `env:"SECRET_VALUE,sensitive,oneof=a|b"`
+	}
+
+	type Regex struct {
+		Value string `env:"SECRET_VALUE,sensitive,regex=^[0-9]+$"`
+	}
+
+	type Format struct {
+		Value string `env:"SECRET_VALUE,sensitive,format=uuid"`
+	}
+
+	os.Setenv("SECRET_VALUE", "hunter2")
+	defer os.Unsetenv("SECRET_VALUE")
+
+	tests := []struct {
+		name string
+		obj  interface{}
+	}{
+		{name: "conversion", obj: &Number{}},
+		{name: "oneof", obj: &OneOf{}},
+		{name: "regex", obj: &Regex{}},
+		{name: "format", obj: &Format{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			err := minienv.Load(tt.obj)
+
+			// Assert
+			assert.Error(t, err)
+			assert.ErrorContains(t, err, "***")
+			assert.NotContains(t, err.Error(), "hunter2")
+		})
+	}
+}
+
+func TestLoadWithEmitEffective(t *testing.T) {
+	// Arrange
+	type S struct {
+		Port     int    `env:"PORT,default=8080"`
+		Host     string `env:"HOST"`
+		Password string `env:"PASSWORD,sensitive"`
+		Region   string `env:"REGION,optional"`
+	}
+
+	os.Setenv("APP_HOST", "localhost")
+	defer os.Unsetenv("APP_HOST")
+
+	os.Setenv("APP_PASSWORD", "secret")
+	defer os.Unsetenv("APP_PASSWORD")
+
+	var buf bytes.Buffer
+
+	// Act
+	var s S
+	err := minienv.Load(&s, minienv.WithPrefix("APP_"), minienv.WithEmitEffective(&buf))
+
+	// Assert
+	assert.Nil(t, err)
+	assert.Equal(t, "APP_PORT=8080\nAPP_HOST=localhost\nAPP_PASSWORD=***\nAPP_REGION=\n", buf.String())
+	assert.NotContains(t, buf.String(), "secret")
+}
+
+func TestLoadWithEmitEffectiveAndResolvedValues(t *testing.T) {
+	// Arrange
+	type S struct {
+		Host    string        `env:"HOST" json:"host"`
+		Port    int           `env:"PORT" json:"port"`
+		Token   string        `env:"TOKEN,sensitive" json:"token"`
+		Alias   string        `env:"ALIAS,defaultfrom=Host"`
+		Region  string        `env:"REGION"`
+		Zone    string        `env:"ZONE,join=REGION|PORT,joinsep=-"`
+		Timeout time.Duration `env:"TIMEOUT,default=5s"`
+		Tags    []string      `env:"TAGS,optional"`
+	}
+
+	os.Setenv("APP_CONFIG", `{"host": "blob-host", "token": "secret"}`)
+	defer os.Unsetenv("APP_CONFIG")
+
+	os.Setenv("PORT", "8080")
+	defer os.Unsetenv("PORT")
+
+	os.Setenv("REGION", "eu")
+	defer os.Unsetenv("REGION")
+
+	os.Setenv("TAGS", "a|b")
+	defer os.Unsetenv("TAGS")
+
+	var buf bytes.Buffer
+
+	// Act
+	var s S
+	err := minienv.Load(&s, minienv.WithJSONBlob("APP_CONFIG"), minienv.WithEmitEffective(&buf))
+
+	// Assert
+	assert.Nil(t, err)
+	assert.Equal(t, "HOST=blob-host\nPORT=8080\nTOKEN=***\nALIAS=blob-host\nREGION=eu\nZONE=eu-8080\nTIMEOUT=5s\nTAGS=a|b\n", buf.String())
+	assert.NotContains(t, buf.String(), "secret")
+}
+
+type roundTrip struct {
+	EnableCache bool        `env:"DISABLE_CACHE,negate"`
+	Umask       os.FileMode `env:"UMASK"`
+	Secret      string      `env:"SECRET,base64"`
+	Verbose     int         `env:"VERBOSE,count=v"`
+	Perms       int         `env:"PERMS,bitflags"`
+	Timeout     int         `env:"TIMEOUT,in=seconds,store=ms"`
+}
+
+var roundTripFlags = map[string]int{"read": 1, "write": 2, "exec": 4}
+
+func TestLoadWithEmitEffectiveRoundTrip(t *testing.T) {
+	// Arrange
+	env := map[string]string{
+		"DISABLE_CACHE": "true",
+		"UMASK":         "0644",
+		"SECRET":        "aGVsbG8=",
+		"VERBOSE":       "vvv",
+		"PERMS":         "read|write",
+		"TIMEOUT":       "5",
+	}
+
+	for k, v := range env {
+		os.Setenv(k, v)
+		defer os.Unsetenv(k)
+	}
+
+	var buf bytes.Buffer
+
+	// Act
+	var s roundTrip
+	err := minienv.Load(&s, minienv.WithBitFlags(roundTripFlags), minienv.WithEmitEffective(&buf))
+	assert.Nil(t, err)
+
+	for k := range env {
+		os.Unsetenv(k)
+	}
+
+	var reloaded roundTrip
+	err = minienv.Load(&reloaded, minienv.WithBitFlags(roundTripFlags), minienv.WithReader(&buf))
+
+	// Assert
+	assert.Nil(t, err)
+	assert.Equal(t, s, reloaded)
+	assert.Equal(t, roundTrip{Umask: 0644, Secret: "hello", Verbose: 3, Perms: 3, Timeout: 5000}, reloaded)
+}
+
+func TestLoadWithEmitEffectiveRoundTripFromJSONBlob(t *testing.T) {
+	// Arrange
+	os.Setenv("APP_CONFIG", `{"EnableCache": false, "Umask": 420, "Secret": "hello", "Verbose": 3, "Perms": 5, "Timeout": 5000}`)
+	defer os.Unsetenv("APP_CONFIG")
+
+	var buf bytes.Buffer
+
+	// Act
+	var s roundTrip
+	err := minienv.Load(&s, minienv.WithBitFlags(roundTripFlags), minienv.WithJSONBlob("APP_CONFIG"), minienv.WithEmitEffective(&buf))
+	assert.Nil(t, err)
+
+	var reloaded roundTrip
+	err = minienv.Load(&reloaded, minienv.WithBitFlags(roundTripFlags), minienv.WithReader(strings.NewReader(buf.String())))
+
+	// Assert
+	assert.Nil(t, err)
+	assert.Equal(t, "DISABLE_CACHE=true\nUMASK=0644\nSECRET=aGVsbG8=\nVERBOSE=vvv\nPERMS=exec|read\nTIMEOUT=5\n", buf.String())
+	assert.Equal(t, s, reloaded)
+}
+
+func TestLoadWithEmitEffectiveAndDefaultable(t *testing.T) {
+	// Arrange
+	var buf bytes.Buffer
+
+	// Act
+	var e emitDefaultable
+	err := minienv.Load(&e, minienv.WithEmitEffective(&buf))
+
+	// Assert
+	assert.Nil(t, err)
+	assert.Equal(t, "EMIT_HOST=localhost\n", buf.String())
+}
+
+type emitDefaultable struct {
+	Host string `env:"EMIT_HOST"`
+}
+
+func (e *emitDefaultable) SetDefaults() {
+	e.Host = "localhost"
+}
+
+func TestLoadWithEmitEffectiveAndSkipZeroValues(t *testing.T) {
+	// Arrange
+	type S struct {
+		Host    string            `env:"HOST"`
+		Port    int               `env:"PORT,optional"`
+		Debug   bool              `env:"DEBUG,optional"`
+		Name    string            `env:"NAME,optional"`
+		Tags    []string          `env:"TAGS,optional"`
+		Labels  map[string]string `env:"LABELS,optional"`
+		Retries int               `env:"RETRIES,default=3"`
+		Verbose bool              `env:"VERBOSE,default=false"`
+	}
+
+	os.Setenv("HOST", "localhost")
+	defer os.Unsetenv("HOST")
+
+	os.Setenv("DEBUG", "false")
+	defer os.Unsetenv("DEBUG")
+
+	os.Setenv("RETRIES", "0")
+	defer os.Unsetenv("RETRIES")
+
+	var buf bytes.Buffer
+
+	// Act
+	var s S
+	err := minienv.Load(&s, minienv.WithEmitEffective(&buf), minienv.WithSkipZeroValues())
+
+	// Assert
+	assert.Nil(t, err)
+	assert.Equal(t, "HOST=localhost\nRETRIES=0\n# VERBOSE=false\n", buf.String())
+
+	// the minimal output loads the same values again
+	empty := func(string) (string, bool) { return "", false }
+
+	var reloaded S
+	err = minienv.Load(&reloaded, minienv.WithEnvSource(empty), minienv.WithReader(&buf))
+	assert.Nil(t, err)
+	assert.Equal(t, s, reloaded)
+}
+
+func TestLoadWithEmitEffectiveAndError(t *testing.T) {
+	// Arrange
+	type S struct {
+		Host string `env:"HOST"`
+	}
+
+	var buf bytes.Buffer
+
+	// Act
+	var s S
+	err := minienv.Load(&s, minienv.WithEmitEffective(&buf))
+
+	// Assert
+	assert.Error(t, err)
+	assert.Empty(t, buf.String())
+}
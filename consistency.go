@@ -0,0 +1,65 @@
+package minienv
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// A value that a field of a consistency group resolved to
+type consistentValue struct {
+	field string
+	key   string
+	value string
+
+	// the value as it is reported in errors, masked for sensitive fields
+	display string
+}
+
+// Records the value of a field for its consistency group.
+func addConsistentValue(config *LoadConfig, field string, key string, val string, t tag) {
+	display := val
+	if t.sensitive {
+		display = config.mask(val)
+	}
+
+	config.consistency[t.consistency] = append(config.consistency[t.consistency], consistentValue{
+		field:   field,
+		key:     key,
+		value:   val,
+		display: display,
+	})
+}
+
+// Checks that all fields of each consistency group resolved to the same value.
+// Fields without a value are not part of the comparison.
+func checkConsistency(groups map[string][]consistentValue) error {
+	names := make([]string, 0, len(groups))
+	for name := range groups {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+
+	var errs []error
+	for _, name := range names {
+		values := groups[name]
+
+		consistent := !slices.ContainsFunc(values, func(v consistentValue) bool {
+			return v.value != values[0].value
+		})
+
+		if consistent {
+			continue
+		}
+
+		described := make([]string, 0, len(values))
+		for _, v := range values {
+			described = append(described, fmt.Sprintf("%s (%s) = \"%s\"", v.field, v.key, v.display))
+		}
+
+		errs = append(errs, fmt.Errorf("consistency group \"%s\" has conflicting values: %s", name, strings.Join(described, ", ")))
+	}
+
+	return errors.Join(errs...)
+}
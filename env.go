@@ -1,19 +1,161 @@
 package minienv
 
 import (
+	"context"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"hash"
+	"io"
+	"log/slog"
+	"math"
+	"net"
+	"net/mail"
+	"net/netip"
+	"net/url"
 	"os"
+	"path/filepath"
 	"reflect"
+	"regexp"
+	"slices"
 	"strconv"
 	"strings"
+	"time"
+	"unicode"
 )
 
 type Option func(*LoadConfig) error
 
+// Setter can be implemented by types that parse their value from the environment themselves.
+// It takes precedence over encoding.TextUnmarshaler and the built-in conversions
+// and is also used for the elements of slices and maps.
+type Setter interface {
+	SetEnv(value string) error
+}
+
+// Defaultable can be implemented by structs that set their defaults in code.
+// SetDefaults is called on the struct passed to Load before any field is loaded,
+// so the defaults are only replaced by values from actual sources, not by the `default` option.
+type Defaultable interface {
+	SetDefaults()
+}
+
 type LoadConfig struct {
 	Prefix string
 	Values map[string]string
+
+	// the prefix that is stripped from keys in errors, set by LoadPrefixed
+	errorPrefix string
+
+	// values that take precedence over every other source, including the environment
+	Overrides map[string]string
+
+	// the function the environment is read from, nil if the environment is read with os.LookupEnv
+	envSource func(string) (string, bool)
+
+	// records which source supplied each key in Values
+	origins map[string]Source
+
+	// whether repeated keys in env-files are joined instead of overwritten
+	accumulate bool
+
+	// whether unresolved references like ${HOST} in env-files are an error
+	strictExpansion bool
+
+	// whether an env-file or reader was already parsed by one of the options
+	fileLoaded bool
+
+	// whether fields are only required if they have the required option
+	optionalByDefault bool
+
+	// functions that validate the struct after all of its fields were loaded
+	validators []func(any) error
+
+	// collects where every loaded field got its value from
+	audit []AuditEntry
+
+	// whether keys are lowercased before they are looked up
+	lowercaseKeys bool
+
+	// only fields whose lookup key starts with this are loaded
+	keyFilter string
+
+	// the separator between the options of a tag
+	tagSeparator rune
+
+	// the profile whose tag variants like `env_prod` are preferred over the `env` tag, empty if not used
+	profile string
+
+	// the logger that is used to report warnings
+	logger *slog.Logger
+
+	// whether a warning is logged if the environment overrides a different fallback value
+	warnOnConflict bool
+
+	// the context of the current load that is passed to providers
+	ctx context.Context
+
+	// external sources that are consulted if a key is not in the environment
+	providers []Provider
+
+	// the maximum duration of a single provider lookup, 0 if unlimited
+	providerTimeout time.Duration
+
+	// the number of attempts of a provider lookup and the wait between failed ones
+	providerAttempts int
+	providerBackoff  time.Duration
+
+	// the key of a variable that contains the whole config as JSON, empty if not used
+	blobKey string
+
+	// the bits of named flags that are combined for fields with the bitflags option
+	bitFlags map[string]int
+
+	// the decoded values of the fields whose keys were present in the JSON blob of the current load,
+	// nil if there was no blob
+	blobFields map[fieldID]reflect.Value
+
+	// whether the struct of the current load set its own defaults through Defaultable
+	defaultsApplied bool
+
+	// functions that are run after a specific field was set, keyed by field name or env key
+	normalizers map[string][]func(reflect.Value) error
+
+	// the JSON Schema the struct is validated against after loading, nil if not used
+	schema map[string]interface{}
+
+	// a snapshot of the environment that is used instead of the live one, nil if not frozen
+	env map[string]string
+
+	// functions that decode the raw bytes of a value into fields of a specific type
+	codecs map[reflect.Type]func([]byte, reflect.Value) error
+
+	// the writer the effective configuration is written to after a successful load, nil if not used
+	emit io.Writer
+
+	// the fields whose final values are written to emit, in the order in which they were loaded
+	emitted []emittedField
+
+	// whether fields at their zero value are left out when the effective configuration is written
+	skipZero bool
+
+	// the function that masks the values of sensitive fields before they are reported
+	mask func(string) string
+
+	// the values of all fields that were resolved during the current load, keyed by lookup key
+	resolved map[string]string
+
+	// the values of the fields of each consistency group that were resolved during the current load
+	consistency map[string][]consistentValue
+
+	// the reader that fields with the stdin option read from and the maximum number of bytes read
+	stdin      io.Reader
+	stdinLimit int64
 }
 
 // This struct hold all the metadata about a found "env"-tag for a field
@@ -26,26 +168,270 @@ type tag struct {
 
 	// This is the default value for the variable, can be empty and therefore invalid
 	defaultValue string
+
+	// This is the delimiter that is used to split values for slice fields
+	delimiter string
+
+	// This is a flag that tells us if the delimiter was set with the split option, so byte slices are parsed as numbers
+	explicitSplit bool
+
+	// This is the separator between the key and the value of a map entry
+	kvSeparator string
+
+	// These are the names that are looked up in order if the name itself has no value
+	aliases []string
+
+	// These are the values the field is restricted to, empty if any value is allowed
+	oneOf []string
+
+	// This is the pattern the value has to match, nil if there is none
+	regex *regexp.Regexp
+
+	// This is a flag that tells us if the allowed values are compared as integers instead of strings
+	oneOfInt bool
+
+	// This is a flag that tells us if the value must be redacted when reported
+	sensitive bool
+
+	// This is a flag that tells us if a bool value should be inverted
+	negate bool
+
+	// This is the name of a special format the value is written in, can be empty
+	format string
+
+	// This is the maximum number of decimal places the value may have, -1 if unrestricted
+	scale int
+
+	// These are the minimum and maximum number of entries a map may have, -1 if unrestricted
+	minCount int
+	maxCount int
+
+	// These are the bounds of the value of a number field, nil if unbounded
+	minValue *float64
+	maxValue *float64
+
+	// This is a flag that tells us if a number outside of its bounds is clamped to them instead of failing
+	clamp bool
+
+	// This is the order in which sources are consulted, empty if the global order is used
+	sources []Source
+
+	// This is a flag that tells us if map values are merged over the default instead of replacing it
+	mergeDefault bool
+
+	// This is a flag that tells us if a nested struct must be configured completely or not at all
+	atomic bool
+
+	// This is a flag that tells us if a time is written as a duration relative to now
+	relative bool
+
+	// This is a flag that tells us if the value is a list of flag names that are combined into an int
+	bitflags bool
+
+	// This is a flag that tells us if empty elements of slices and maps are dropped
+	skipEmpty bool
+
+	// This is the name of another field whose value is used if this field has no value
+	defaultFrom string
+
+	// This is the list of allowed schemes for url fields, empty if all are allowed
+	schemes []string
+
+	// This is a flag that tells us if the integer keys of a map must not have gaps
+	contiguous bool
+
+	// This is a flag that tells us if the value is decoded as JSON
+	json bool
+
+	// This is the unit of plain numbers for duration fields, 0 if plain numbers are not allowed
+	unit time.Duration
+
+	// This maps values to the canonical value that is set instead
+	mapping map[string]string
+
+	// This is the computed result of the expr option, used like a default
+	expr string
+
+	// This is the name of a decoder registered with RegisterType, empty if not used
+	typeName string
+
+	// This is a flag that tells us if the default should be used when the value can't be converted
+	onErrorDefault bool
+
+	// This is a flag that tells us if a value of "-" should be read from stdin
+	stdin bool
+
+	// This is how the keys of a map are transformed before they are inserted, empty if they are kept
+	keyTransform string
+
+	// This is a flag that tells us if the first element of a slice is the number of the remaining ones
+	countPrefix bool
+
+	// This is a flag that tells us if the field collects the errors of the other fields of its struct
+	collectErrors bool
+
+	// This is the substring whose occurrences in the value are counted, empty if the value is parsed
+	count string
+
+	// This is a flag that tells us if the value should be resolved to an absolute path
+	absPath bool
+
+	// These are the layouts a time is tried to be parsed with in order, empty if RFC 3339 is used
+	layouts []string
+
+	// This is the pattern of the prefix of every element of an indexed slice of structs, like "SVC_%d_"
+	indexed string
+
+	// This is a flag that tells us if duplicate elements of a slice should be removed
+	dedup bool
+
+	// These are the hash algorithm and the key of the variable containing the checksum of the value
+	verifyAlgorithm string
+	verifyKey       string
+
+	// These are the transforms of the chain option that are applied to the value in order
+	chain []string
+
+	// These are the keys whose values are joined into this field and the separator between them
+	join          []string
+	joinSeparator string
+
+	// This is the name of the group whose fields must all resolve to the same value, empty if there is none
+	consistency string
+
+	// This is a flag that tells us if everything after an unquoted "#" should be removed from the value
+	stripComment bool
+
+	// This is a flag that tells us if the elements of a slice are "name=weight" entries of a name/weight struct
+	weighted bool
+
+	// This is a flag that tells us if the value is base64 encoded and has to be decoded before it is set
+	base64 bool
+
+	// These are the units of the in and store options, as well as the factor between them, 0 if numbers are not converted
+	inUnit     string
+	storeUnit  string
+	conversion float64
 }
 
+// The delimiter that is used for slices if no `split` option was specified
+const defaultDelimiter = "|"
+
+// The separator between the key and the value of map entries if no `kvsep` option was specified
+const defaultKeyValueSeparator = ":"
+
+// The separator between the options of a tag if no other was specified with WithTagSeparator
+const defaultTagSeparator = ','
+
+// The maximum number of bytes read from stdin if no other limit was specified with WithStdin
+const defaultStdinLimit = 1 << 20
+
+// Types that are not handled by their kind but parsed explicitly
+var (
+	durationType    = reflect.TypeOf(time.Duration(0))
+	timeType        = reflect.TypeOf(time.Time{})
+	fileModeType    = reflect.TypeOf(os.FileMode(0))
+	weekdayType     = reflect.TypeOf(time.Weekday(0))
+	monthType       = reflect.TypeOf(time.Month(0))
+	mailAddressType = reflect.TypeOf(mail.Address{})
+	urlType         = reflect.TypeOf(url.URL{})
+	ipNetType       = reflect.TypeOf(net.IPNet{})
+	errorType       = reflect.TypeOf((*error)(nil)).Elem()
+)
+
 // Load variables from the environment into the provided struct.
 // It will try to match environment variables to field that contain an `env` tag.
 //
 // The obj must be a pointer to a struct.
 // Additional options can be supplied for overriding environment variables.
 func Load(obj interface{}, options ...Option) error {
+	return LoadContext(context.Background(), obj, options...)
+}
+
+// LoadContext works like Load, but passes the context to any providers
+// that are consulted during loading.
+func LoadContext(ctx context.Context, obj interface{}, options ...Option) error {
+	_, err := load(ctx, obj, options...)
+	return err
+}
+
+// LoadPrefixed loads the struct with all of its keys under the given prefix,
+// which is useful to load a subtree like "DB_" into a dedicated struct.
+// It is a shorthand for Load with WithPrefix as the first option,
+// except that keys in errors are reported without the prefix.
+func LoadPrefixed(obj interface{}, prefix string, options ...Option) error {
+	stripPrefix := func(c *LoadConfig) error {
+		c.errorPrefix = prefix
+		return nil
+	}
+
+	return Load(obj, append([]Option{WithPrefix(prefix), stripPrefix}, options...)...)
+}
+
+// ReloadPrefix loads only the fields of an already loaded struct whose lookup key starts
+// with the given prefix, all other fields are left untouched.
+// The lookup key includes any prefix supplied with WithPrefix.
+func ReloadPrefix(obj interface{}, prefix string, options ...Option) error {
+	config, err := newLoadConfig(options...)
+	if err != nil {
+		return err
+	}
+
+	config.keyFilter = prefix
+	return loadStruct(obj, config)
+}
+
+// Loads the struct and returns the config that was used, including the collected audit.
+func load(ctx context.Context, obj interface{}, options ...Option) (*LoadConfig, error) {
+	config, err := newLoadConfig(options...)
+	if err != nil {
+		return nil, err
+	}
+
+	config.ctx = ctx
+
+	err = loadStruct(obj, config)
+	if err != nil {
+		return nil, err
+	}
+
+	return config, nil
+}
+
+// Creates a new config and applies all options to it.
+func newLoadConfig(options ...Option) (*LoadConfig, error) {
 	// read in any overrides the user wants to do
 	config := LoadConfig{
-		Values: make(map[string]string),
+		ctx:          context.Background(),
+		Values:       make(map[string]string),
+		origins:      make(map[string]Source),
+		resolved:     make(map[string]string),
+		consistency:  make(map[string][]consistentValue),
+		normalizers:  make(map[string][]func(reflect.Value) error),
+		logger:       slog.Default(),
+		tagSeparator: defaultTagSeparator,
+		mask:         redact,
+		stdin:        os.Stdin,
+		stdinLimit:   defaultStdinLimit,
 	}
 
 	for _, option := range options {
 		err := option(&config)
 		if err != nil {
-			return err
+			return nil, err
 		}
 	}
 
+	// the snapshot is taken from the process environment, so it would silently replace the source
+	if config.env != nil && config.envSource != nil {
+		return nil, errors.New("WithFrozenEnv can't be combined with WithEnvSource")
+	}
+
+	return &config, nil
+}
+
+// Validates that obj is a pointer to a struct and fills it using the config.
+func loadStruct(obj interface{}, config *LoadConfig) error {
 	// we can only set things if we receive a pointer that points to a struct
 	p := reflect.ValueOf(obj)
 	if p.Kind() != reflect.Ptr {
@@ -57,176 +443,2634 @@ func Load(obj interface{}, options ...Option) error {
 		return ErrInvalidInput
 	}
 
+	// defaults set in code are the base that everything else overrides, reloads keep the loaded values instead
+	d, ok := obj.(Defaultable)
+	config.defaultsApplied = ok && config.keyFilter == ""
+	if config.defaultsApplied {
+		d.SetDefaults()
+	}
+
+	// the JSON blob provides the base values that are then overridden field by field
+	if config.blobKey != "" {
+		err := loadJSONBlob(obj, config)
+		if err != nil {
+			return err
+		}
+	}
+
 	// this will recursively fill the struct
-	err := handleStruct(s, &config)
+	err := handleStruct(s, parseTags(s.Type(), config), config)
+	if err != nil {
+		return err
+	}
+
+	err = checkConsistency(config.consistency)
 	if err != nil {
 		return err
 	}
 
+	if config.schema != nil {
+		err = validateSchema(obj, config.schema, config)
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, validate := range config.validators {
+		err = validate(obj)
+		if err != nil {
+			return err
+		}
+	}
+
+	if config.emit != nil {
+		return emitEffective(config.emit, config)
+	}
+
 	return nil
 }
 
-// Handles a struct recursively by iterating over its fields
-// and then setting the field with the appropiate variable if one was found.
-func handleStruct(s reflect.Value, config *LoadConfig) error {
+// Decodes the JSON blob into the struct if the variable containing it is present.
+// Like every other key, the variable can be forced with WithOverrides.
+func loadJSONBlob(obj interface{}, config *LoadConfig) error {
+	blob, found := config.Overrides[config.blobKey]
+	if !found {
+		blob, found = lookupEnv(config.blobKey, config)
+	}
+
+	if !found {
+		blob, found = config.Values[config.blobKey]
+	}
+
+	if !found {
+		return nil
+	}
+
+	// a reload must not touch the fields outside of its prefix, so the blob is decoded into a copy
+	// and its values are only applied to the fields that are reloaded
+	decoded := reflect.ValueOf(obj)
+	if config.keyFilter != "" {
+		decoded = reflect.New(decoded.Elem().Type())
+	}
+
+	err := json.Unmarshal([]byte(blob), decoded.Interface())
+	if err != nil {
+		return LoadError{
+			Field: config.blobKey,
+			Err:   fmt.Errorf("failed to decode JSON blob: %w", err),
+		}
+	}
+
+	// only fields whose keys the blob contains count as set by it, even if their value is false or 0
+	config.blobFields = make(map[fieldID]reflect.Value)
+	collectBlobFields(reflect.ValueOf(obj).Elem(), decoded.Elem(), json.RawMessage(blob), config.blobFields)
+
+	return nil
+}
+
+// Identifies a field by its address, the type tells apart a nested struct and its first field
+type fieldID struct {
+	addr uintptr
+	typ  reflect.Type
+}
+
+func idOf(field reflect.Value) fieldID {
+	return fieldID{addr: field.Addr().Pointer(), typ: field.Type()}
+}
+
+// Records the fields of the struct whose keys are present in the JSON object together with their values
+// in the decoded struct, matching the keys like encoding/json does, including nested and embedded structs.
+func collectBlobFields(s reflect.Value, decoded reflect.Value, raw json.RawMessage, fields map[fieldID]reflect.Value) {
+	var object map[string]json.RawMessage
+	if json.Unmarshal(raw, &object) != nil {
+		return
+	}
+
 	for i := 0; i < s.NumField(); i++ {
-		// handle recursive cases
+		structField := s.Type().Field(i)
 		field := s.Field(i)
-		if field.Kind() == reflect.Struct {
-			err := handleStruct(field, config)
-			if err != nil {
-				return err
-			}
 
+		name, tagged := structField.Tag.Lookup("json")
+		name, _, _ = strings.Cut(name, ",")
+		if name == "-" {
 			continue
 		}
 
-		// Check if the tag is present skip if not
-		tag, found, err := parseTag(s.Type().Field(i))
-		if !found {
+		// embedded structs without a name in the tag are flattened into the object of their parent
+		if structField.Anonymous && name == "" && field.Kind() == reflect.Struct {
+			collectBlobFields(field, decoded.Field(i), raw, fields)
 			continue
 		}
 
-		// something went wrong parsing the tag
-		if err != nil {
-			return LoadError{
-				Field: s.Type().Field(i).Name,
-				Err:   err,
-			}
+		if !structField.IsExported() {
+			continue
 		}
 
-		// check if we can actually set the field
-		if !field.IsValid() || !field.CanSet() {
-			return LoadError{
-				Field: s.Type().Field(i).Name,
-				Err:   errors.New("field is not valid or cannot be set"),
-			}
+		if !tagged || name == "" {
+			name = structField.Name
 		}
 
-		// read the value from the environment and from any our overrides
-		lookup := tag.name
-		if config.Prefix != "" && !strings.HasPrefix(lookup, config.Prefix) {
-			lookup = fmt.Sprintf("%s%s", config.Prefix, lookup)
+		value, found := lookupJSONKey(object, name)
+		if !found {
+			continue
 		}
 
-		envVal, envExists := os.LookupEnv(lookup)
-		fallbackVal, fallbackExists := config.Values[lookup]
-
-		// guard against the cases where we don't have any valeu that we can set
-		if !envExists && !fallbackExists && tag.required && tag.defaultValue == "" {
-			return LoadError{
-				Field: s.Type().Field(i).Name,
-				Err:   errors.New("required field has no value and no default"),
-			}
+		fields[idOf(field)] = decoded.Field(i)
+		if field.Kind() == reflect.Struct {
+			collectBlobFields(field, decoded.Field(i), value, fields)
 		}
+	}
+}
 
-		// Priority:
-		// 1. Environment
-		// 2. Fallback
-		// 3. Default
-		var val string
-		if envExists {
-			val = envVal
-		} else if fallbackExists {
-			val = fallbackVal
-		} else {
-			val = tag.defaultValue
-		}
+// Looks up the key in the JSON object, preferring an exact match over a case-insensitive one like encoding/json
+func lookupJSONKey(object map[string]json.RawMessage, name string) (json.RawMessage, bool) {
+	if value, found := object[name]; found {
+		return value, true
+	}
 
-		// update the affected field
-		err = setField(field, val)
-		if err != nil {
-			// we wrap the error for some metadata
-			return LoadError{
-				Field: s.Type().Field(i).Name,
-				Err:   err,
-			}
+	for key, value := range object {
+		if strings.EqualFold(key, name) {
+			return value, true
 		}
 	}
 
-	return nil
+	return nil, false
 }
 
-// Sets a field based on the kind and the provided value
-// This here tries to convert the value to the appropiate type
-func setField(f reflect.Value, val string) error {
-	k := f.Kind()
-	switch k {
-	// string
-	case reflect.String:
-		f.SetString(val)
+// The parsed `env` tag of a struct field together with the results of parseTag
+type fieldTag struct {
+	tag   tag
+	found bool
+	err   error
+}
 
-	// int
-	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
-		i, err := strconv.Atoi(val)
-		if err != nil {
-			return err
-		}
+// Parses the tags of all fields of the struct type so that each of them is only parsed once per load.
+func parseTags(t reflect.Type, config *LoadConfig) []fieldTag {
+	tags := make([]fieldTag, t.NumField())
+	for i := range tags {
+		tags[i].tag, tags[i].found, tags[i].err = parseTag(t.Field(i), config)
+	}
 
-		f.SetInt(int64(i))
+	return tags
+}
 
-	// bool
-	case reflect.Bool:
-		b, err := strconv.ParseBool(val)
-		if err != nil {
+// Handles a struct recursively by iterating over its fields
+// and then setting the field with the appropiate variable if one was found.
+// The tags are the parsed tags of the fields of the struct, see parseTags.
+func handleStruct(s reflect.Value, tags []fieldTag, config *LoadConfig) error {
+	// a field with the errors option collects the errors of all other fields instead of failing the load
+	errorsIndex := findErrorsField(tags)
+
+	var collected []error
+	fail := func(err error) error {
+		if errorsIndex < 0 {
 			return err
 		}
 
-		f.SetBool(b)
+		collected = append(collected, err)
+		return nil
+	}
 
-	// float
-	case reflect.Float32, reflect.Float64:
-		fl, err := strconv.ParseFloat(val, 64)
-		if err != nil {
-			return err
+	var unset []int
+	for i := 0; i < s.NumField(); i++ {
+		if i == errorsIndex {
+			continue
 		}
 
-		f.SetFloat(fl)
+		// handle recursive cases
+		field := s.Field(i)
+		if isNestedStruct(field) && !isValueStruct(s.Type().Field(i), tags[i], config) {
+			// atomic structs are either loaded completely or not at all
+			err := tags[i].err
+			if err != nil {
+				err = fail(LoadError{
+					Field: s.Type().Field(i).Name,
+					Err:   err,
+				})
 
-	// anything else is not supported
-	default:
-		return fmt.Errorf("unsupported type: %v", k.String())
-	}
+				if err != nil {
+					return err
+				}
 
-	return nil
-}
+				continue
+			}
 
-// Parses the `env` tag and returns the bundled information about the tag.
-// The first return value is the tag itself, the second return value is a flag indicating if the tag was found
-// and the third return value is an error if the tag was invalid.
-func parseTag(field reflect.StructField) (tag, bool, error) {
-	required := true
-	var defaultVal string
+			nestedTags := parseTags(field.Type(), config)
+			if tags[i].tag.atomic {
+				provided, err := checkAtomicStruct(field, nestedTags, config)
+				if err != nil {
+					err = fail(LoadError{
+						Field: s.Type().Field(i).Name,
+						Err:   err,
+					})
 
-	value, found := field.Tag.Lookup("env")
-	if !found {
-		return tag{}, false, nil
-	}
+					if err != nil {
+						return err
+					}
 
-	// check any tag options
-	parts := strings.Split(value, ",")
-	for _, p := range parts[1:] {
-		trimmed := strings.TrimSpace(p)
-		splitted := strings.Split(trimmed, "=")
+					continue
+				}
 
-		// tag is optional
-		if splitted[0] == "optional" {
-			required = false
+				if !provided {
+					continue
+				}
+			}
 
-		} else if splitted[0] == "default" {
+			err = handleStruct(field, nestedTags, config)
+			if err != nil {
+				err = fail(err)
+				if err != nil {
+					return err
+				}
+			}
 
-			// if we have more or less than 2 elements we have an invalid tag
+			continue
+		}
+
+		source, err := handleField(field, s.Type().Field(i), tags[i], config)
+		if err != nil {
+			// we wrap the error for some metadata
+			err = fail(LoadError{
+				Field: s.Type().Field(i).Name,
+				Err:   err,
+			})
+
+			if err != nil {
+				return err
+			}
+
+			continue
+		}
+
+		if source == SourceUnset {
+			unset = append(unset, i)
+		}
+	}
+
+	// fields without a value can copy the value of another field once all fields are resolved
+	for _, i := range unset {
+		err := copyDefaultFrom(s, i, tags[i].tag)
+		if err == nil {
+			err = joinValues(s.Field(i), tags[i].tag, config)
+		}
+
+		if err != nil {
+			err = fail(LoadError{
+				Field: s.Type().Field(i).Name,
+				Err:   err,
+			})
+
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	if len(collected) > 0 {
+		s.Field(errorsIndex).Set(reflect.ValueOf(errors.Join(collected...)))
+	}
+
+	return nil
+}
+
+// Returns the index of the field with the errors option, -1 if the struct has none.
+func findErrorsField(tags []fieldTag) int {
+	for i, t := range tags {
+		if t.found && t.err == nil && t.tag.collectErrors {
+			return i
+		}
+	}
+
+	return -1
+}
+
+// Copies the value of the field named in the defaultfrom option into the field at index i.
+func copyDefaultFrom(s reflect.Value, i int, t tag) error {
+	if t.defaultFrom == "" {
+		return nil
+	}
+
+	fromField, found := s.Type().FieldByName(t.defaultFrom)
+	if !found {
+		return fmt.Errorf("unknown field in defaultfrom: %s", t.defaultFrom)
+	}
+
+	// values of unexported fields can't be copied with reflection
+	if !fromField.IsExported() {
+		return fmt.Errorf("unexported field in defaultfrom: %s", t.defaultFrom)
+	}
+
+	from, err := s.FieldByIndexErr(fromField.Index)
+	if err != nil {
+		return fmt.Errorf("field %s in defaultfrom can't be read: %w", t.defaultFrom, err)
+	}
+
+	field := s.Field(i)
+	if !from.Type().AssignableTo(field.Type()) {
+		return fmt.Errorf("field %s of type %s can't be used as default for type %s", t.defaultFrom, from.Type(), field.Type())
+	}
+
+	field.Set(from)
+	return nil
+}
+
+// Sets a field with the join option to the resolved values of its components joined by the separator.
+func joinValues(field reflect.Value, t tag, config *LoadConfig) error {
+	if len(t.join) == 0 {
+		return nil
+	}
+
+	parts := make([]string, 0, len(t.join))
+	for _, name := range t.join {
+		key := lookupKey(tag{name: name}, config)
+
+		val, found := config.resolved[key]
+		if !found {
+			return fmt.Errorf("join component \"%s\" has no value", reportedKey(key, config))
+		}
+
+		parts = append(parts, val)
+	}
+
+	field.SetString(strings.Join(parts, t.joinSeparator))
+	return nil
+}
+
+// Handles a single field by looking up its value and setting it.
+// The returned source describes where the value was taken from,
+// it is empty for fields that were skipped because they have no `env` tag
+// and for indexed slices, whose elements are audited field by field.
+func handleField(field reflect.Value, structField reflect.StructField, ft fieldTag, config *LoadConfig) (Source, error) {
+	// Check if the tag is present skip if not
+	if !ft.found {
+		return "", nil
+	}
+
+	// something went wrong parsing the tag
+	if ft.err != nil {
+		return "", ft.err
+	}
+
+	tag := ft.tag
+
+	// check if we can actually set the field
+	if !field.IsValid() || !field.CanSet() {
+		return "", errors.New("field is not valid or cannot be set")
+	}
+
+	// every element of an indexed slice is loaded like a struct under its own prefix
+	if tag.indexed != "" {
+		if !indexedMatchesFilter(tag, config) {
+			return "", nil
+		}
+
+		return loadIndexed(field, tag, config)
+	}
+
+	// read the value from the environment and from any our overrides
+	lookup := lookupKey(tag, config)
+	if !strings.HasPrefix(lookup, config.keyFilter) {
+		return "", nil
+	}
+
+	// an alias is used if the name itself has no value
+	if len(tag.aliases) > 0 {
+		lookup = resolveAlias(lookup, tag, config)
+	}
+
+	// the value is only written once the load is done, so that joined and copied values are included
+	emitted := -1
+	if config.emit != nil {
+		emitted = len(config.emitted)
+		config.emitted = append(config.emitted, emittedField{key: lookup, field: field, tag: tag})
+	}
+
+	// joined fields are assembled from other fields once all of them are resolved
+	if len(tag.join) > 0 {
+		return SourceUnset, nil
+	}
+
+	// a value from the JSON blob or from SetDefaults is only replaced by values from actual sources, not by defaults
+	// during a reload the blob was decoded into a copy, so its value is applied to the field here
+	lookupTag := tag
+	blobValue, inBlob := config.blobFields[idOf(field)]
+	if inBlob {
+		field.Set(blobValue)
+	}
+
+	fromBlob := inBlob || (config.defaultsApplied && !field.IsZero())
+	if fromBlob {
+		lookupTag.required = false
+		lookupTag.defaultValue = ""
+	}
+
+	// the value of another field is used instead, which is only known once all fields are resolved
+	if tag.defaultFrom != "" {
+		lookupTag.required = false
+	}
+
+	val, source, err := fetchFieldValue(lookup, lookupTag, config)
+	if err != nil {
+		return "", err
+	}
+
+	if fromBlob && source == SourceUnset {
+		prefilled := SourceJSONBlob
+		if !inBlob {
+			prefilled = SourceDefault
+		}
+
+		config.audit = append(config.audit, newAuditEntry(structField.Name, lookup, prefilled, "", tag, config.mask))
+		return prefilled, nil
+	}
+
+	if source == SourceUnset && tag.defaultFrom != "" {
+		return SourceUnset, nil
+	}
+
+	// a "-" means the value is piped in through stdin
+	if tag.stdin && val == "-" {
+		val, err = readStdin(config)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	// the value is written as it was read, so that loading the output again applies the same transforms
+	if emitted >= 0 && source != SourceUnset {
+		config.emitted[emitted].raw, config.emitted[emitted].hasRaw = val, true
+	}
+
+	// some tools pass values with a trailing comment like "foo # comment"
+	if tag.stripComment && source != SourceUnset {
+		val = stripComment(val)
+	}
+
+	// compare the checksum of the value with the one stored in another variable
+	if tag.verifyKey != "" && source != SourceUnset {
+		err = verifyChecksum(val, tag, config)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	// secrets are often stored base64 encoded
+	if tag.base64 && source != SourceUnset {
+		decoded, err := base64.StdEncoding.DecodeString(val)
+		if err != nil {
+			return "", fmt.Errorf("invalid base64 value: %w", err)
+		}
+
+		val = string(decoded)
+	}
+
+	// run the value through the transforms of the chain option
+	if len(tag.chain) > 0 && source != SourceUnset {
+		val, err = applyChain(val, tag.chain)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	// translate synonyms into their canonical value
+	if mapped, found := tag.mapping[val]; found && source != SourceUnset {
+		val = mapped
+	}
+
+	// paths are resolved against the working directory at load time
+	if tag.absPath && source != SourceUnset {
+		val, err = filepath.Abs(val)
+		if err != nil {
+			return "", fmt.Errorf("failed to resolve absolute path: %w", err)
+		}
+	}
+
+	// errors must not reveal the values of sensitive fields, so they only contain the masked value
+	shown := val
+	if tag.sensitive {
+		shown = config.mask(val)
+	}
+
+	// make sure the value matches its format and is one of the allowed values, unless there is no value at all
+	if source != SourceUnset {
+		err = validateFormat(val, shown, tag)
+		if err != nil {
+			return "", err
+		}
+
+		err = checkOneOf(val, shown, tag)
+		if err != nil {
+			return "", err
+		}
+
+		if tag.regex != nil && !tag.regex.MatchString(val) {
+			return "", fmt.Errorf("value \"%s\" does not match the pattern \"%s\"", shown, tag.regex)
+		}
+	}
+
+	// make sure the value doesn't have more decimal places than allowed
+	if tag.scale >= 0 {
+		err = checkScale(val, shown, tag.scale)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	// flag names are combined into a single integer before it is set
+	raw := val
+	if tag.bitflags {
+		raw, err = combineBitFlags(val, tag, config)
+		if err != nil {
+			return "", concealError(err, shown, tag)
+		}
+	}
+
+	// update the affected field, a field without a value is left at its zero value so that pointers stay nil
+	// and unset can be told apart from zero
+	if source != SourceUnset {
+		err = setField(field, raw, tag, config)
+		err = concealError(err, shown, tag)
+	} else {
+		field.Set(reflect.Zero(field.Type()))
+	}
+	if err != nil && tag.onErrorDefault && tag.defaultValue != "" && source != SourceDefault {
+		// the malformed value is replaced by the default instead of failing the load
+		config.logger.Warn("failed to convert value, using the default instead", "key", lookup, "error", err)
+
+		field.Set(reflect.Zero(field.Type()))
+		val, source = tag.defaultValue, SourceDefault
+
+		val, err = resolveDefault(val)
+		if err == nil {
+			err = setField(field, val, tag, config)
+		}
+
+		if emitted >= 0 {
+			config.emitted[emitted].raw = val
+		}
+	}
+
+	if err != nil {
+		return "", err
+	}
+
+	// make sure maps have an allowed number of entries and numbers are within their bounds, unless there is no value at all
+	if source != SourceUnset {
+		err = checkCount(field, tag)
+		if err != nil {
+			return "", err
+		}
+
+		clamped, err := checkBounds(field, tag)
+		if err != nil {
+			return "", concealError(err, shown, tag)
+		}
+
+		if clamped {
+			config.logger.Warn("value is out of bounds, clamping it", "key", lookup)
+			val = fmt.Sprint(field.Interface())
+		}
+	}
+
+	// run any normalizers that were registered for this field
+	err = normalizeField(field, structField.Name, tag.name, config)
+	if err != nil {
+		return "", err
+	}
+
+	// remember the value so that joined fields can use it
+	if source != SourceUnset {
+		config.resolved[lookup] = val
+	}
+
+	// remember the value so that it can be compared with the other fields of its group
+	if tag.consistency != "" && source != SourceUnset {
+		addConsistentValue(config, structField.Name, lookup, val, tag)
+	}
+
+	config.audit = append(config.audit, newAuditEntry(structField.Name, lookup, source, val, tag, config.mask))
+	return source, nil
+}
+
+// Looks up the key in the frozen environment snapshot if there is one, otherwise in the environment source.
+// Only the keys of the environment are lowercased by WithLowercaseKeys, the other sources use the keys as they are.
+func lookupEnv(key string, config *LoadConfig) (string, bool) {
+	if config.lowercaseKeys {
+		key = strings.ToLower(key)
+	}
+
+	if config.env != nil {
+		val, found := config.env[key]
+		return val, found
+	}
+
+	if config.envSource != nil {
+		return config.envSource(key)
+	}
+
+	return os.LookupEnv(key)
+}
+
+// Returns the key as it is shown in errors, which is without the prefix of LoadPrefixed
+func reportedKey(key string, config *LoadConfig) string {
+	return strings.TrimPrefix(key, config.errorPrefix)
+}
+
+// Builds the key that is used for the lookup by applying the prefix to the name of the tag
+func lookupKey(t tag, config *LoadConfig) string {
+	key := t.name
+	if config.Prefix != "" && !strings.HasPrefix(key, config.Prefix) {
+		key = fmt.Sprintf("%s%s", config.Prefix, key)
+	}
+
+	return key
+}
+
+// Returns the first of the key and the keys of the aliases of the field that has a value in the overrides,
+// the environment or the fallback values. Aliases follow the same prefix rules as the name, if none has a value the key is kept.
+func resolveAlias(key string, t tag, config *LoadConfig) string {
+	keys := []string{key}
+	for _, alias := range t.aliases {
+		aliasTag := t
+		aliasTag.name = alias
+		keys = append(keys, lookupKey(aliasTag, config))
+	}
+
+	for _, k := range keys {
+		if _, found := config.Overrides[k]; found {
+			return k
+		}
+
+		if _, found := lookupEnv(k, config); found {
+			return k
+		}
+
+		if _, found := config.Values[k]; found {
+			return k
+		}
+	}
+
+	return key
+}
+
+// Checks whether any of the fields of an atomic struct were provided and if so, that all required ones were.
+// The first return value is false if none of the fields were provided.
+func checkAtomicStruct(s reflect.Value, tags []fieldTag, config *LoadConfig) (bool, error) {
+	provided := false
+	var missing []string
+
+	for _, ft := range tags {
+		if !ft.found || ft.err != nil {
+			continue
+		}
+
+		t := ft.tag
+
+		key := lookupKey(t, config)
+		_, overridden := config.Overrides[key]
+		_, envExists := lookupEnv(key, config)
+		_, fallbackExists := config.Values[key]
+
+		providerExists := false
+		if !overridden && !envExists && !fallbackExists {
+			var err error
+			_, providerExists, err = lookupProviders(key, config)
+			if err != nil {
+				return false, err
+			}
+		}
+
+		if overridden || envExists || providerExists || fallbackExists {
+			provided = true
+		} else if t.required && t.defaultValue == "" {
+			missing = append(missing, reportedKey(key, config))
+		}
+	}
+
+	if provided && len(missing) > 0 {
+		return true, fmt.Errorf("struct is only partially configured, missing: %s", strings.Join(missing, ", "))
+	}
+
+	return provided, nil
+}
+
+// Checks whether the keys of the elements of an indexed slice can start with the prefix of a reload.
+// A reload of "SVC_" or "SVC_1_" both touch a slice indexed like "SVC_%d_".
+func indexedMatchesFilter(t tag, config *LoadConfig) bool {
+	stem := t.indexed[:strings.Index(t.indexed, "%d")]
+	stem = config.Prefix + strings.ReplaceAll(stem, "%%", "%")
+
+	return strings.HasPrefix(stem, config.keyFilter) || strings.HasPrefix(config.keyFilter, stem)
+}
+
+// Loads a slice of structs whose elements are configured under prefixes like "SVC_0_", "SVC_1_" and so on.
+// Indices are probed in ascending order until the first one that has none of the keys of the struct.
+func loadIndexed(field reflect.Value, t tag, config *LoadConfig) (Source, error) {
+	// all elements share the same type, so their tags only need to be parsed once
+	tags := parseTags(field.Type().Elem(), config)
+
+	elements := reflect.MakeSlice(field.Type(), 0, 0)
+	for i := 0; ; i++ {
+		elementConfig := *config
+		elementConfig.Prefix = config.Prefix + fmt.Sprintf(t.indexed, i)
+
+		// a reload that touches the slice reloads all of its elements completely
+		elementConfig.keyFilter = ""
+
+		element := reflect.New(field.Type().Elem()).Elem()
+		provided, err := checkAtomicStruct(element, tags, &elementConfig)
+		if err != nil {
+			return "", fmt.Errorf("failed to load element at index %d: %w", i, err)
+		}
+
+		if !provided {
+			break
+		}
+
+		err = handleStruct(element, tags, &elementConfig)
+		config.audit = elementConfig.audit
+		config.emitted = elementConfig.emitted
+		if err != nil {
+			return "", fmt.Errorf("failed to load element at index %d: %w", i, err)
+		}
+
+		elements = reflect.Append(elements, element)
+	}
+
+	if elements.Len() == 0 {
+		if t.required {
+			return "", fmt.Errorf("required field has no elements under \"%s\"", reportedKey(config.Prefix+fmt.Sprintf(t.indexed, 0), config))
+		}
+
+		return SourceUnset, nil
+	}
+
+	// the elements record their own sources
+	field.Set(elements)
+	return "", nil
+}
+
+// Fetches the value for a key from the environment, the fallback values or the default of the tag.
+// The second return value describes which of these sources the value was taken from.
+func fetchFieldValue(key string, t tag, config *LoadConfig) (string, Source, error) {
+	// overrides are forced, even over the sources the field declares
+	if val, found := config.Overrides[key]; found {
+		return val, SourceOverride, nil
+	}
+
+	// the field declares its own order of sources
+	if len(t.sources) > 0 {
+		for _, source := range t.sources {
+			val, found, err := lookupSource(source, key, t, config)
+			if err != nil {
+				return "", "", err
+			}
+
+			if found {
+				return val, source, nil
+			}
+		}
+
+		if t.required {
+			return "", "", errors.New("required field has no value in any of its sources")
+		}
+
+		return "", SourceUnset, nil
+	}
+
+	envVal, envExists := lookupEnv(key, config)
+	fallbackVal, fallbackExists := config.Values[key]
+
+	// providers are only asked if the environment doesn't already contain the key
+	var providerVal string
+	var providerExists bool
+	if !envExists {
+		var err error
+		providerVal, providerExists, err = lookupProviders(key, config)
+		if err != nil {
+			return "", "", err
+		}
+	}
+
+	// guard against the cases where we don't have any value that we can set
+	if !envExists && !providerExists && !fallbackExists && t.required && t.defaultValue == "" {
+		return "", "", errors.New("required field has no value and no default")
+	}
+
+	// the environment silently wins, which might not be what the user expects
+	if config.warnOnConflict && envExists && fallbackExists && envVal != fallbackVal {
+		config.logger.Warn("environment variable overrides a different fallback value", "key", key, "overridden", config.origins[key])
+	}
+
+	// Priority (below the overrides):
+	// 1. Environment
+	// 2. Providers
+	// 3. Fallback
+	// 4. Default
+	if envExists {
+		return envVal, SourceEnv, nil
+	} else if providerExists {
+		return providerVal, SourceProvider, nil
+	} else if fallbackExists {
+		return fallbackVal, config.origins[key], nil
+	} else if t.defaultValue != "" {
+		val, err := resolveDefault(t.defaultValue)
+		return val, SourceDefault, err
+	}
+
+	return "", SourceUnset, nil
+}
+
+// Functions that compute defaults like `default=@hostname` when they are used
+var defaultSentinels = map[string]func() (string, error){
+	"@hostname": os.Hostname,
+	"@pid": func() (string, error) {
+		return strconv.Itoa(os.Getpid()), nil
+	},
+	"@pwd": os.Getwd,
+}
+
+// Resolves a default that refers to a sentinel like @hostname, other defaults are returned as they are.
+func resolveDefault(val string) (string, error) {
+	if !strings.HasPrefix(val, "@") {
+		return val, nil
+	}
+
+	resolve, found := defaultSentinels[val]
+	if !found {
+		return "", fmt.Errorf("unknown default sentinel: %s", val)
+	}
+
+	resolved, err := resolve()
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve default %s: %w", val, err)
+	}
+
+	return resolved, nil
+}
+
+// Looks up the value for a key in a single source.
+func lookupSource(source Source, key string, t tag, config *LoadConfig) (string, bool, error) {
+	switch source {
+	case SourceEnv:
+		val, found := lookupEnv(key, config)
+		return val, found, nil
+
+	case SourceProvider:
+		return lookupProviders(key, config)
+
+	case SourceFile, SourceFallback:
+		val, found := config.Values[key]
+		return val, found && config.origins[key] == source, nil
+
+	case SourceDefault:
+		val, err := resolveDefault(t.defaultValue)
+		return val, t.defaultValue != "", err
+	}
+
+	return "", false, nil
+}
+
+// Combines a list of flag names into an integer by OR-ing the bits registered with WithBitFlags.
+func combineBitFlags(val string, t tag, config *LoadConfig) (string, error) {
+	if val == "" {
+		return "0", nil
+	}
+
+	mask := 0
+	for _, name := range splitValue(val, t) {
+		bit, found := config.bitFlags[name]
+		if !found {
+			return "", fmt.Errorf("unknown flag: %s", name)
+		}
+
+		mask |= bit
+	}
+
+	return strconv.Itoa(mask), nil
+}
+
+// Runs all normalizers that were registered for either the field name or the env key.
+func normalizeField(f reflect.Value, name string, key string, config *LoadConfig) error {
+	// the slices are shared by every load of a Loader, so they are concatenated into a new one
+	normalizers := config.normalizers[name]
+	if key != name {
+		normalizers = slices.Concat(normalizers, config.normalizers[key])
+	}
+
+	for _, normalize := range normalizers {
+		err := normalize(f)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Checks if the value is a struct that needs to be handled recursively instead of being set directly
+func isNestedStruct(f reflect.Value) bool {
+	return f.Kind() == reflect.Struct && !implementsSetter(f) && !implementsTextUnmarshaler(f) && f.Type() != mailAddressType && f.Type() != urlType && f.Type() != ipNetType
+}
+
+// Checks if the type is a slice of structs whose elements are loaded field by field instead of being parsed from a single value
+func isIndexableSlice(t reflect.Type, config *LoadConfig) bool {
+	if t.Kind() != reflect.Slice {
+		return false
+	}
+
+	_, sliceCodec := config.codecs[t]
+	_, elementCodec := config.codecs[t.Elem()]
+	return !sliceCodec && !elementCodec && isNestedStruct(reflect.New(t.Elem()).Elem())
+}
+
+// Checks if the struct field is parsed from a single value because of its tag or a codec,
+// either as a range, as JSON or with a registered type
+func isValueStruct(field reflect.StructField, ft fieldTag, config *LoadConfig) bool {
+	if _, found := config.codecs[field.Type]; found {
+		return true
+	}
+
+	t := ft.tag
+	return ft.err == nil && (t.format == "range" || t.json || t.typeName != "")
+}
+
+// Checks if a pointer to the value implements Setter
+func implementsSetter(f reflect.Value) bool {
+	return f.CanAddr() && f.Addr().Type().Implements(reflect.TypeOf((*Setter)(nil)).Elem())
+}
+
+// Checks if a pointer to the value implements encoding.TextUnmarshaler
+func implementsTextUnmarshaler(f reflect.Value) bool {
+	return f.CanAddr() && f.Addr().Type().Implements(reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem())
+}
+
+// Matches versions like 1.2.3, v1.2.3 or 1.2.3-rc.1+build.5
+var semverRegex = regexp.MustCompile(`^v?(0|[1-9]\d*)\.(0|[1-9]\d*)\.(0|[1-9]\d*)(-[0-9A-Za-z.-]+)?(\+[0-9A-Za-z.-]+)?$`)
+
+// Matches UUIDs like 123e4567-e89b-12d3-a456-426614174000 in any case
+var uuidRegex = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+
+// Matches hostnames made of dot-separated labels of letters, digits and inner hyphens (RFC 1123)
+var hostnameRegex = regexp.MustCompile(`^([a-zA-Z0-9]([a-zA-Z0-9-]{0,61}[a-zA-Z0-9])?)(\.[a-zA-Z0-9]([a-zA-Z0-9-]{0,61}[a-zA-Z0-9])?)*$`)
+
+// Validates the value against formats that don't change how the value is converted.
+// Errors contain the shown value instead, which is masked for sensitive fields.
+func validateFormat(val string, shown string, t tag) error {
+	switch t.format {
+	case "semver":
+		if !semverRegex.MatchString(val) {
+			return fmt.Errorf("value \"%s\" is not a valid semantic version", shown)
+		}
+	case "email":
+		addr, err := mail.ParseAddress(val)
+		if err != nil || addr.Address != val {
+			return fmt.Errorf("value \"%s\" is not a valid email address", shown)
+		}
+	case "url":
+		u, err := url.Parse(val)
+		if err != nil || u.Scheme == "" || u.Host == "" {
+			return fmt.Errorf("value \"%s\" is not a valid url", shown)
+		}
+	case "uuid":
+		if !uuidRegex.MatchString(val) {
+			return fmt.Errorf("value \"%s\" is not a valid uuid", shown)
+		}
+	case "hostname":
+		if len(val) > 253 || !hostnameRegex.MatchString(val) {
+			return fmt.Errorf("value \"%s\" is not a valid hostname", shown)
+		}
+	case "ipv4":
+		addr, err := netip.ParseAddr(val)
+		if err != nil || !addr.Is4() {
+			return fmt.Errorf("value \"%s\" is not a valid ipv4 address", shown)
+		}
+	case "ipv6":
+		addr, err := netip.ParseAddr(val)
+		if err != nil || !addr.Is6() {
+			return fmt.Errorf("value \"%s\" is not a valid ipv6 address", shown)
+		}
+	}
+
+	return nil
+}
+
+// Checks that a numeric value has at most the given number of decimal places
+func checkScale(val string, shown string, scale int) error {
+	_, decimals, found := strings.Cut(val, ".")
+	if !found {
+		return nil
+	}
+
+	if len(decimals) > scale {
+		return fmt.Errorf("value \"%s\" has %d decimal places, but at most %d are allowed", shown, len(decimals), scale)
+	}
+
+	return nil
+}
+
+// Sets a field based on the kind and the provided value
+// This here tries to convert the value to the appropiate type
+func setField(f reflect.Value, val string, t tag, config *LoadConfig) error {
+	// registered decoders are used regardless of the type of the field
+	if t.typeName != "" {
+		return setRegisteredType(f, val, t.typeName)
+	}
+
+	// codecs receive the raw bytes of the value, which chain=base64 can decode beforehand
+	if codec, found := config.codecs[f.Type()]; found {
+		return codec([]byte(val), f)
+	}
+
+	// the whole value is decoded as JSON, which allows structures the delimiters can't express,
+	// pointers are only allocated if there is a value, so an unset optional pointer stays nil
+	if t.json {
+		if val == "" {
+			return nil
+		}
+
+		err := json.Unmarshal([]byte(val), f.Addr().Interface())
+		if err != nil {
+			return fmt.Errorf("invalid JSON: %w", err)
+		}
+
+		return nil
+	}
+
+	// relative times are written as a duration that is added to the current time
+	if t.relative {
+		d, err := time.ParseDuration(val)
+		if err != nil {
+			return fmt.Errorf("invalid relative time: %w", err)
+		}
+
+		f.Set(reflect.ValueOf(time.Now().Add(d)))
+		return nil
+	}
+
+	// times can be written in any of the layouts of the tag, the first one that matches is used
+	if len(t.layouts) > 0 {
+		var err error
+		for _, layout := range t.layouts {
+			var parsed time.Time
+			parsed, err = time.Parse(layout, val)
+			if err == nil {
+				f.Set(reflect.ValueOf(parsed))
+				return nil
+			}
+		}
+
+		// with a single layout the error of the parser is the most helpful one
+		if len(t.layouts) == 1 {
+			return fmt.Errorf("invalid time \"%s\": %w", val, err)
+		}
+
+		return fmt.Errorf("value \"%s\" matches none of the layouts %s", val, strings.Join(t.layouts, ", "))
+	}
+
+	// ranges are written as "min-max" and assigned to the Min and Max fields
+	if t.format == "range" {
+		return setRange(f, val)
+	}
+
+	// types that know how to parse themselves take precedence over everything else
+	if implementsSetter(f) {
+		return f.Addr().Interface().(Setter).SetEnv(val)
+	}
+
+	if implementsTextUnmarshaler(f) {
+		err := f.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(val))
+		if err != nil {
+			return fmt.Errorf("failed to unmarshal \"%s\" into %s: %w", val, f.Type(), err)
+		}
+
+		return nil
+	}
+
+	// durations are int64 under the hood, so they need to be handled before the kind switch
+	if f.Type() == durationType {
+		// plain numbers are interpreted in the unit of the tag
+		if t.unit != 0 {
+			n, err := strconv.ParseFloat(val, 64)
+			if err == nil {
+				f.SetInt(int64(n * float64(t.unit)))
+				return nil
+			}
+		}
+
+		d, err := time.ParseDuration(val)
+		if err != nil {
+			return fmt.Errorf("invalid duration \"%s\": %w", val, err)
+		}
+
+		f.SetInt(int64(d))
+		return nil
+	}
+
+	// file modes are written as octal numbers like 0644 or 0o755
+	if f.Type() == fileModeType {
+		digits := strings.TrimPrefix(strings.TrimPrefix(val, "0o"), "0O")
+		mode, err := strconv.ParseUint(digits, 8, 32)
+		if err != nil {
+			return fmt.Errorf("invalid file mode \"%s\": %w", val, err)
+		}
+
+		f.SetUint(mode)
+		return nil
+	}
+
+	// weekdays and months are written by name or by their number
+	if f.Type() == weekdayType {
+		day, err := parseCalendarName(val, int(time.Sunday), int(time.Saturday), func(i int) string { return time.Weekday(i).String() })
+		if err != nil {
+			return fmt.Errorf("invalid weekday \"%s\": %w", val, err)
+		}
+
+		f.SetInt(int64(day))
+		return nil
+	}
+
+	if f.Type() == monthType {
+		month, err := parseCalendarName(val, int(time.January), int(time.December), func(i int) string { return time.Month(i).String() })
+		if err != nil {
+			return fmt.Errorf("invalid month \"%s\": %w", val, err)
+		}
+
+		f.SetInt(int64(month))
+		return nil
+	}
+
+	// urls are parsed with their own parser, both as value and as pointer
+	if f.Type() == urlType || f.Type() == reflect.PointerTo(urlType) {
+		u, err := url.Parse(val)
+		if err != nil {
+			return err
+		}
+
+		if len(t.schemes) > 0 && !slices.Contains(t.schemes, u.Scheme) {
+			return fmt.Errorf("url scheme \"%s\" is not allowed, expected one of: %s", u.Scheme, strings.Join(t.schemes, ", "))
+		}
+
+		if f.Kind() == reflect.Ptr {
+			f.Set(reflect.ValueOf(u))
+		} else {
+			f.Set(reflect.ValueOf(*u))
+		}
+
+		return nil
+	}
+
+	// mail addresses are parsed with their own parser, both as value and as pointer
+	if f.Type() == mailAddressType || f.Type() == reflect.PointerTo(mailAddressType) {
+		address, err := mail.ParseAddress(val)
+		if err != nil {
+			return err
+		}
+
+		if f.Kind() == reflect.Ptr {
+			f.Set(reflect.ValueOf(address))
+		} else {
+			f.Set(reflect.ValueOf(*address))
+		}
+
+		return nil
+	}
+
+	// networks are written in CIDR notation, ip addresses are handled by encoding.TextUnmarshaler
+	if f.Type() == ipNetType {
+		_, network, err := net.ParseCIDR(val)
+		if err != nil {
+			return fmt.Errorf("invalid network \"%s\": %w", val, err)
+		}
+
+		f.Set(reflect.ValueOf(*network))
+		return nil
+	}
+
+	// numbers written in one unit are stored in another, like seconds in a field holding milliseconds
+	if t.conversion != 0 {
+		converted, err := convertUnit(val, t.conversion, f.Kind())
+		if err != nil {
+			return err
+		}
+
+		val = converted
+	}
+
+	// byte slices receive the raw bytes of the value, only an explicit split option parses them as numbers
+	if f.Kind() == reflect.Slice && f.Type().Elem().Kind() == reflect.Uint8 && (t.base64 || !t.explicitSplit) {
+		f.SetBytes([]byte(val))
+		return nil
+	}
+
+	k := f.Kind()
+	switch k {
+	// string
+	case reflect.String:
+		f.SetString(val)
+
+	// int
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		// the number of occurrences is used instead, so "vvv" results in 3
+		if t.count != "" {
+			f.SetInt(int64(strings.Count(val, t.count)))
+			return nil
+		}
+
+		i, err := strconv.Atoi(val)
+		if err != nil {
+			return err
+		}
+
+		f.SetInt(int64(i))
+
+	// unsigned integers, the bit size of the type is used to detect overflows
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		u, err := strconv.ParseUint(val, 10, f.Type().Bits())
+		if err != nil {
+			return err
+		}
+
+		f.SetUint(u)
+
+	// bool
+	case reflect.Bool:
+		b, err := strconv.ParseBool(val)
+		if err != nil {
+			return err
+		}
+
+		f.SetBool(b != t.negate)
+
+	// float
+	case reflect.Float32, reflect.Float64:
+		var fl float64
+		var err error
+		if t.format == "rate" {
+			fl, err = parseRate(val)
+		} else {
+			fl, err = strconv.ParseFloat(val, 64)
+		}
+
+		if err != nil {
+			return err
+		}
+
+		f.SetFloat(fl)
+
+	// slice
+	case reflect.Slice:
+		// a bracketed list like [a,b,c] is always split on commas, regardless of the split option
+		parts := splitBracketed(val, t)
+
+		// the first element announces how many elements follow it
+		if t.countPrefix {
+			var err error
+			parts, err = consumeCountPrefix(parts)
+			if err != nil {
+				return err
+			}
+		}
+		s := reflect.MakeSlice(f.Type(), len(parts), len(parts))
+		for i, p := range parts {
+			var err error
+			if t.weighted {
+				err = setWeightedEntry(s.Index(i), p)
+			} else {
+				err = setField(s.Index(i), p, t, config)
+			}
+
+			if err != nil {
+				return fmt.Errorf("failed to parse element at index %d: %w", i, err)
+			}
+		}
+
+		if t.dedup {
+			s = dedupSlice(s, parts)
+		}
+
+		f.Set(s)
+
+	// map
+	case reflect.Map:
+		m := reflect.MakeMap(f.Type())
+
+		// the default entries are used as a base that the actual value is merged over
+		if t.mergeDefault {
+			err := setMapEntries(m, t.defaultValue, t, config)
+			if err != nil {
+				return err
+			}
+		}
+
+		err := setMapEntries(m, val, t, config)
+		if err != nil {
+			return err
+		}
+
+		if t.contiguous {
+			err = checkContiguousKeys(m)
+			if err != nil {
+				return err
+			}
+		}
+
+		f.Set(m)
+
+	// pointers are allocated and the value is set on what they point to, which also covers nested pointers
+	case reflect.Ptr:
+		p := reflect.New(f.Type().Elem())
+		err := setField(p.Elem(), val, t, config)
+		if err != nil {
+			return err
+		}
+
+		f.Set(p)
+
+	// empty interfaces receive the raw string, other interfaces can't be populated
+	case reflect.Interface:
+		if f.NumMethod() != 0 {
+			return fmt.Errorf("unsupported type: %v", f.Type().String())
+		}
+
+		f.Set(reflect.ValueOf(val))
+
+	// anything else is not supported
+	default:
+		return fmt.Errorf("unsupported type: %v", k.String())
+	}
+
+	return nil
+}
+
+// Splits the value of a slice or map into its elements.
+// An empty value results in no elements, empty elements are only dropped with the skipempty option.
+// Elements wrapped in double quotes may contain the delimiter and are always kept, even when empty.
+// Quotes anywhere else, or without a closing quote, are kept as part of the element.
+func splitValue(val string, t tag) []string {
+	if val == "" {
+		return nil
+	}
+
+	var parts []string
+	var quoted []bool
+
+	start := 0
+	for i := 0; i < len(val); i++ {
+		// a quote only counts if it opens an element and is closed right before a delimiter or the end
+		if i == start && val[i] == '"' {
+			if end := closingQuote(val, i+1, t.delimiter); end != -1 {
+				i = end
+				continue
+			}
+		}
+
+		if strings.HasPrefix(val[i:], t.delimiter) {
+			part, q := unquoteElement(val[start:i])
+			parts, quoted = append(parts, part), append(quoted, q)
+
+			i += len(t.delimiter) - 1
+			start = i + 1
+		}
+	}
+
+	part, q := unquoteElement(val[start:])
+	parts, quoted = append(parts, part), append(quoted, q)
+
+	if !t.skipEmpty {
+		return parts
+	}
+
+	nonEmpty := make([]string, 0, len(parts))
+	for i, p := range parts {
+		if p != "" || quoted[i] {
+			nonEmpty = append(nonEmpty, p)
+		}
+	}
+
+	return nonEmpty
+}
+
+// Validates that the first element is the number of the remaining elements and returns them.
+func consumeCountPrefix(parts []string) ([]string, error) {
+	if len(parts) == 0 {
+		return nil, errors.New("missing count prefix")
+	}
+
+	count, err := strconv.Atoi(parts[0])
+	if err != nil {
+		return nil, fmt.Errorf("invalid count prefix \"%s\": %w", parts[0], err)
+	}
+
+	if count != len(parts)-1 {
+		return nil, fmt.Errorf("count prefix announces %d elements, but %d were given", count, len(parts)-1)
+	}
+
+	return parts[1:], nil
+}
+
+// Removes duplicate elements from a slice while keeping the first occurrence of each.
+// Elements are compared after conversion, so "1" and "01" are duplicates in an []int,
+// elements of types that can't be compared are compared by their raw value instead.
+func dedupSlice(s reflect.Value, raw []string) reflect.Value {
+	comparable := s.Type().Elem().Comparable()
+	seen := make(map[interface{}]bool, s.Len())
+
+	unique := reflect.MakeSlice(s.Type(), 0, s.Len())
+	for i := 0; i < s.Len(); i++ {
+		var key interface{} = raw[i]
+		if comparable {
+			key = s.Index(i).Interface()
+		}
+
+		if seen[key] {
+			continue
+		}
+
+		seen[key] = true
+		unique = reflect.Append(unique, s.Index(i))
+	}
+
+	return unique
+}
+
+// Splits a list that is surrounded by brackets on commas and trims the space around its elements,
+// any other value is split with splitValue.
+func splitBracketed(val string, t tag) []string {
+	if len(val) < 2 || val[0] != '[' || val[len(val)-1] != ']' {
+		return splitValue(val, t)
+	}
+
+	bracketed := t
+	bracketed.delimiter = ","
+
+	parts := splitValue(strings.TrimSpace(val[1:len(val)-1]), bracketed)
+	for i, p := range parts {
+		parts[i] = strings.TrimSpace(p)
+	}
+
+	return parts
+}
+
+// Returns the index of the first quote from the given index on that is followed by the delimiter or the end of the value, or -1.
+func closingQuote(val string, from int, delimiter string) int {
+	for i := from; i < len(val); i++ {
+		if val[i] == '"' && (i == len(val)-1 || strings.HasPrefix(val[i+1:], delimiter)) {
+			return i
+		}
+	}
+
+	return -1
+}
+
+// Strips the surrounding double quotes of a list element and reports whether it was quoted.
+func unquoteElement(s string) (string, bool) {
+	if len(s) >= 2 && s[0] == '"' && s[len(s)-1] == '"' {
+		return s[1 : len(s)-1], true
+	}
+
+	return s, false
+}
+
+// Parses an entry like "a=3" into the name and the weight field of the struct.
+func setWeightedEntry(f reflect.Value, entry string) error {
+	name, weight, found := strings.Cut(entry, "=")
+	if !found {
+		return fmt.Errorf("invalid weighted entry \"%s\": missing \"=\"", entry)
+	}
+
+	w, err := strconv.ParseInt(weight, 10, f.Field(1).Type().Bits())
+	if err != nil {
+		return fmt.Errorf("invalid weight in entry \"%s\": %w", entry, err)
+	}
+
+	f.Field(0).SetString(name)
+	f.Field(1).SetInt(w)
+	return nil
+}
+
+// Removes everything after the first "#" that is not inside single or double quotes,
+// as well as the whitespace in front of it.
+func stripComment(val string) string {
+	var quote byte
+	for i := 0; i < len(val); i++ {
+		switch {
+		case quote != 0:
+			if val[i] == quote {
+				quote = 0
+			}
+		case val[i] == '"' || val[i] == '\'':
+			quote = val[i]
+		case val[i] == '#':
+			return strings.TrimRight(val[:i], " \t")
+		}
+	}
+
+	return val
+}
+
+// Parses entries in the form of "key:value|key:value" and sets them in the map, the separators can be changed with split and kvsep.
+// Duplicate keys are overwritten by the last occurrence, also if they only became equal by the keytransform option.
+func setMapEntries(m reflect.Value, val string, t tag, config *LoadConfig) error {
+	if val == "" {
+		return nil
+	}
+
+	for i, entry := range splitValue(val, t) {
+		k, v, found := strings.Cut(entry, t.kvSeparator)
+		if !found {
+			return fmt.Errorf("invalid map entry at index %d: missing \"%s\"", i, t.kvSeparator)
+		}
+
+		switch t.keyTransform {
+		case "lower":
+			k = strings.ToLower(k)
+		case "upper":
+			k = strings.ToUpper(k)
+		}
+
+		key := reflect.New(m.Type().Key()).Elem()
+		err := setField(key, k, t, config)
+		if err != nil {
+			return fmt.Errorf("failed to parse key of map entry at index %d: %w", i, err)
+		}
+
+		value := reflect.New(m.Type().Elem()).Elem()
+		err = setField(value, v, t, config)
+		if err != nil {
+			return fmt.Errorf("failed to parse value of map entry at index %d: %w", i, err)
+		}
+
+		m.SetMapIndex(key, value)
+	}
+
+	return nil
+}
+
+// Checks that a map has at least the minimum and at most the maximum number of entries of the tag.
+func checkCount(f reflect.Value, t tag) error {
+	if f.Kind() != reflect.Map {
+		return nil
+	}
+
+	if t.minCount >= 0 && f.Len() < t.minCount {
+		return fmt.Errorf("map has %d entries, but at least %d are required", f.Len(), t.minCount)
+	}
+
+	if t.maxCount >= 0 && f.Len() > t.maxCount {
+		return fmt.Errorf("map has %d entries, but at most %d are allowed", f.Len(), t.maxCount)
+	}
+
+	return nil
+}
+
+// Replaces an error that may contain the value of a sensitive field, like the ones of strconv,
+// by one that only contains the shown value. Errors of other fields are returned unchanged.
+func concealError(err error, shown string, t tag) error {
+	if err == nil || !t.sensitive {
+		return err
+	}
+
+	return fmt.Errorf("invalid value \"%s\"", shown)
+}
+
+// Checks that the value is one of the values of the oneof option.
+// Errors contain the shown value instead, which is masked for sensitive fields.
+func checkOneOf(val string, shown string, t tag) error {
+	if len(t.oneOf) == 0 {
+		return nil
+	}
+
+	found := slices.Contains(t.oneOf, val)
+	if t.oneOfInt {
+		// a value that isn't a number at all fails when it is set
+		n, err := strconv.ParseInt(val, 10, 64)
+		if err != nil {
+			return nil
+		}
+
+		found = slices.ContainsFunc(t.oneOf, func(allowed string) bool {
+			a, _ := strconv.ParseInt(allowed, 10, 64)
+			return a == n
+		})
+	}
+
+	if !found {
+		return fmt.Errorf("value \"%s\" is not one of the allowed values: %s", shown, strings.Join(t.oneOf, ", "))
+	}
+
+	return nil
+}
+
+// Checks that a number field is within the bounds of its min and max options.
+// With the clamp option the field is set to the exceeded bound instead, which is reported by the returned flag.
+func checkBounds(f reflect.Value, t tag) (bool, error) {
+	if t.minValue == nil && t.maxValue == nil {
+		return false, nil
+	}
+
+	var n float64
+	switch {
+	case f.CanInt():
+		n = float64(f.Int())
+	case f.CanUint():
+		n = float64(f.Uint())
+	case f.CanFloat():
+		n = f.Float()
+	default:
+		return false, nil
+	}
+
+	var bound float64
+	switch {
+	case t.minValue != nil && n < *t.minValue:
+		if !t.clamp {
+			return false, fmt.Errorf("value %v is less than the minimum of %v", n, *t.minValue)
+		}
+
+		bound = *t.minValue
+	case t.maxValue != nil && n > *t.maxValue:
+		if !t.clamp {
+			return false, fmt.Errorf("value %v is greater than the maximum of %v", n, *t.maxValue)
+		}
+
+		bound = *t.maxValue
+	default:
+		return false, nil
+	}
+
+	switch {
+	case f.CanInt():
+		f.SetInt(int64(bound))
+	case f.CanUint():
+		f.SetUint(uint64(math.Max(bound, 0)))
+	default:
+		f.SetFloat(bound)
+	}
+
+	return true, nil
+}
+
+// Checks that the integer keys of a map form a contiguous range starting at the smallest key.
+func checkContiguousKeys(m reflect.Value) error {
+	if m.Len() == 0 {
+		return nil
+	}
+
+	keys := make([]int64, 0, m.Len())
+	for _, k := range m.MapKeys() {
+		keys = append(keys, k.Int())
+	}
+
+	slices.Sort(keys)
+	for i := 1; i < len(keys); i++ {
+		if keys[i] != keys[i-1]+1 {
+			return fmt.Errorf("map keys are not contiguous, missing key %d", keys[i-1]+1)
+		}
+	}
+
+	return nil
+}
+
+// The byte units that can be used with the in and store options
+var byteUnits = map[string]float64{
+	"b":     1,
+	"bytes": 1,
+	"kb":    1e3,
+	"mb":    1e6,
+	"gb":    1e9,
+	"tb":    1e12,
+	"kib":   1 << 10,
+	"mib":   1 << 20,
+	"gib":   1 << 30,
+	"tib":   1 << 40,
+}
+
+// Checks if the kind is an integer, unsigned integer or float.
+func isNumberKind(k reflect.Kind) bool {
+	switch k {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return true
+	default:
+		return isFloatKind(k)
+	}
+}
+
+// Checks if the kind is a float.
+func isFloatKind(k reflect.Kind) bool {
+	return k == reflect.Float32 || k == reflect.Float64
+}
+
+// Returns the factor a number in the in unit is multiplied with to get the store unit.
+// Both units have to be time units or byte units and the field has to be a plain number.
+func conversionFactor(t reflect.Type, in string, store string) (float64, error) {
+	if t == durationType {
+		return 0, errors.New("in and store can't be used on time.Duration fields, use unit instead")
+	}
+
+	if !isNumberKind(t.Kind()) {
+		return 0, errors.New("in and store can only be used on number fields")
+	}
+
+	if in == "" || store == "" {
+		return 0, errors.New("in and store have to be used together")
+	}
+
+	inTime, inIsTime := durationUnits[in]
+	storeTime, storeIsTime := durationUnits[store]
+	if inIsTime && storeIsTime {
+		return float64(inTime) / float64(storeTime), nil
+	}
+
+	inBytes, inIsBytes := byteUnits[in]
+	storeBytes, storeIsBytes := byteUnits[store]
+	if inIsBytes && storeIsBytes {
+		return inBytes / storeBytes, nil
+	}
+
+	return 0, fmt.Errorf("unsupported unit conversion: %s to %s", in, store)
+}
+
+// Multiplies the number with the conversion factor, integer fields only accept whole results.
+func convertUnit(val string, factor float64, kind reflect.Kind) (string, error) {
+	n, err := strconv.ParseFloat(val, 64)
+	if err != nil {
+		return "", err
+	}
+
+	converted := n * factor
+	if isFloatKind(kind) {
+		return strconv.FormatFloat(converted, 'g', -1, 64), nil
+	}
+
+	// small deviations are caused by the factor not being exact, like 0.001 for ms to s
+	rounded := math.Round(converted)
+	if math.Abs(converted-rounded) > 1e-9*math.Max(1, math.Abs(rounded)) {
+		return "", fmt.Errorf("\"%s\" is not a whole number after the unit conversion", val)
+	}
+
+	return strconv.FormatFloat(rounded, 'f', 0, 64), nil
+}
+
+// The units that can be used with the unit option
+var durationUnits = map[string]time.Duration{
+	"ns":           time.Nanosecond,
+	"nanoseconds":  time.Nanosecond,
+	"us":           time.Microsecond,
+	"microseconds": time.Microsecond,
+	"ms":           time.Millisecond,
+	"milliseconds": time.Millisecond,
+	"s":            time.Second,
+	"seconds":      time.Second,
+	"m":            time.Minute,
+	"minutes":      time.Minute,
+	"h":            time.Hour,
+	"hours":        time.Hour,
+}
+
+// Converts a field name like "HTTPTimeout" into the key "HTTP_TIMEOUT".
+func toSnakeUpper(name string) string {
+	runes := []rune(name)
+
+	var b strings.Builder
+	for i, r := range runes {
+		if i > 0 && unicode.IsUpper(r) {
+			prevLower := unicode.IsLower(runes[i-1]) || unicode.IsDigit(runes[i-1])
+			nextLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+
+			if prevLower || (unicode.IsUpper(runes[i-1]) && nextLower) {
+				b.WriteRune('_')
+			}
+		}
+
+		b.WriteRune(unicode.ToUpper(r))
+	}
+
+	return b.String()
+}
+
+// The names of all options of the `env` tag, used to detect options after an unquoted regex
+var tagOptions = map[string]bool{
+	"optional": true, "required": true, "sensitive": true, "stdin": true, "regex": true, "oneof": true,
+	"aliases": true, "kvsep": true, "keytransform": true, "onerror": true, "negate": true, "map": true,
+	"expr": true, "unit": true, "in": true, "store": true, "json": true, "type": true, "contiguous": true,
+	"schemes": true, "countprefix": true, "errors": true, "count": true, "weighted": true, "base64": true,
+	"clamp": true, "stripcomment": true, "abspath": true, "layout": true, "layouts": true, "indexed": true,
+	"consistency": true, "dedup": true, "verify": true, "chain": true, "join": true, "joinsep": true,
+	"defaultfrom": true, "skipempty": true, "bitflags": true, "relative": true, "atomic": true,
+	"mergedefault": true, "default": true, "format": true, "scale": true, "min": true, "max": true,
+	"sources": true, "split": true,
+}
+
+// Splits the `env` tag at the separator into the name followed by the options.
+// The pattern of the regex option may contain the separator, so it is either quoted like regex='^a,b$'
+// or takes up the rest of the tag, in which case no other option may follow it.
+// A bracketed default like default=[a,b,c] is kept together as well.
+func splitTag(value string, separator rune) ([]string, error) {
+	sep := string(separator)
+	parts := strings.Split(value, sep)
+
+	for i := 1; i < len(parts); i++ {
+		// the bracketed list ends with the first part that contains the closing bracket
+		if strings.HasPrefix(strings.TrimSpace(parts[i]), "default=[") {
+			closed := false
+			for j := i; j < len(parts) && !closed; j++ {
+				if strings.Contains(parts[j], "]") {
+					parts = slices.Concat(parts[:i], []string{strings.Join(parts[i:j+1], sep)}, parts[j+1:])
+					closed = true
+				}
+			}
+
+			if !closed {
+				return nil, errors.New("default list is missing its closing bracket")
+			}
+
+			continue
+		}
+
+		pattern, isRegex := strings.CutPrefix(strings.TrimSpace(parts[i]), "regex=")
+		if !isRegex {
+			continue
+		}
+
+		if !strings.HasPrefix(pattern, "'") {
+			for _, p := range parts[i+1:] {
+				name, _, _ := strings.Cut(strings.TrimSpace(p), "=")
+				if tagOptions[name] {
+					return nil, fmt.Errorf("regex is followed by the %s option, quote the pattern like regex='...' to use options after it", name)
+				}
+			}
+
+			return append(parts[:i], strings.Join(parts[i:], sep)), nil
+		}
+
+		// the quoted pattern ends with the first part that ends with a quote
+		closed := false
+		for j := i; j < len(parts) && !closed; j++ {
+			quoted := strings.TrimPrefix(strings.TrimSpace(strings.Join(parts[i:j+1], sep)), "regex=")
+			if len(quoted) >= 2 && strings.HasSuffix(quoted, "'") {
+				parts = slices.Concat(parts[:i], []string{"regex=" + quoted[1:len(quoted)-1]}, parts[j+1:])
+				closed = true
+			}
+		}
+
+		if !closed {
+			return nil, errors.New("regex pattern is missing its closing quote")
+		}
+	}
+
+	return parts, nil
+}
+
+// Parses the `env` tag and returns the bundled information about the tag.
+// The first return value is the tag itself, the second return value is a flag indicating if the tag was found
+// and the third return value is an error if the tag was invalid.
+func parseTag(field reflect.StructField, config *LoadConfig) (tag, bool, error) {
+	value, found := field.Tag.Lookup("env")
+
+	// the variant of the active profile replaces the regular tag if the field has one
+	if config.profile != "" {
+		if variant, hasVariant := field.Tag.Lookup("env_" + config.profile); hasVariant {
+			value, found = variant, true
+		}
+	}
+
+	if !found {
+		return tag{}, false, nil
+	}
+
+	parts, err := splitTag(value, config.tagSeparator)
+	if err != nil {
+		return tag{}, true, err
+	}
+
+	// like encoding/json, an empty name means that the key is derived from the field name
+	name := strings.TrimSpace(parts[0])
+	if parts[0] == "" {
+		name = toSnakeUpper(field.Name)
+	} else if name == "" {
+		return tag{}, true, errors.New("key must not only consist of whitespace")
+	}
+
+	t := tag{
+		name:        name,
+		required:    !config.optionalByDefault,
+		delimiter:   defaultDelimiter,
+		kvSeparator: defaultKeyValueSeparator,
+		scale:       -1,
+		minCount:    -1,
+		maxCount:    -1,
+	}
+
+	// optional and required contradict each other
+	explicitlyOptional, explicitlyRequired := false, false
+
+	// check any tag options
+	for _, p := range parts[1:] {
+		trimmed := strings.TrimSpace(p)
+		splitted := strings.Split(trimmed, "=")
+
+		switch splitted[0] {
+		// tag is optional
+		case "optional":
+			if explicitlyRequired {
+				return tag{}, true, errors.New("optional and required can't be used together")
+			}
+
+			t.required = false
+			explicitlyOptional = true
+
+		// fields are required by default, unless WithOptionalByDefault is used
+		case "required":
+			if explicitlyOptional {
+				return tag{}, true, errors.New("optional and required can't be used together")
+			}
+
+			t.required = true
+			explicitlyRequired = true
+
+		case "sensitive":
+			t.sensitive = true
+
+		case "stdin":
+			t.stdin = true
+
+		case "regex":
+			pattern := strings.TrimPrefix(trimmed, "regex=")
+			if pattern == "" {
+				return tag{}, true, errors.New("invalid regex tag")
+			}
+
+			re, err := regexp.Compile(pattern)
+			if err != nil {
+				return tag{}, true, fmt.Errorf("invalid regex tag: %w", err)
+			}
+
+			t.regex = re
+
+		case "oneof":
+			if len(splitted) != 2 || splitted[1] == "" {
+				return tag{}, true, errors.New("invalid oneof tag")
+			}
+
+			t.oneOf = strings.Split(splitted[1], "|")
+
+			switch field.Type.Kind() {
+			case reflect.String:
+			case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+				// integers are compared by their value, so that "08" matches 8
+				for _, allowed := range t.oneOf {
+					if _, err := strconv.ParseInt(allowed, 10, 64); err != nil {
+						return tag{}, true, fmt.Errorf("invalid oneof value for an int field: %s", allowed)
+					}
+				}
+
+				t.oneOfInt = true
+			default:
+				return tag{}, true, errors.New("oneof can only be used on string and int fields")
+			}
+
+		case "aliases":
+			if len(splitted) != 2 || splitted[1] == "" {
+				return tag{}, true, errors.New("invalid aliases tag")
+			}
+
+			t.aliases = strings.Split(splitted[1], "|")
+
+		case "kvsep":
+			if field.Type.Kind() != reflect.Map {
+				return tag{}, true, errors.New("kvsep can only be used on map fields")
+			}
+
+			// the separator itself is allowed to contain "="
+			sep := strings.TrimPrefix(trimmed, "kvsep=")
+			if len(splitted) < 2 || sep == "" {
+				return tag{}, true, errors.New("invalid kvsep tag")
+			}
+
+			t.kvSeparator = sep
+
+		case "keytransform":
+			if field.Type.Kind() != reflect.Map {
+				return tag{}, true, errors.New("keytransform can only be used on map fields")
+			}
+
+			if len(splitted) != 2 {
+				return tag{}, true, errors.New("invalid keytransform tag")
+			}
+
+			switch splitted[1] {
+			case "lower", "upper":
+				t.keyTransform = splitted[1]
+			case "none":
+				t.keyTransform = ""
+			default:
+				return tag{}, true, fmt.Errorf("unknown keytransform: %s", splitted[1])
+			}
+
+		case "onerror":
+			if len(splitted) != 2 || splitted[1] != "default" {
+				return tag{}, true, errors.New("invalid onerror tag, only onerror=default is supported")
+			}
+
+			t.onErrorDefault = true
+
+		case "negate":
+			if field.Type.Kind() != reflect.Bool {
+				return tag{}, true, errors.New("negate can only be used on bool fields")
+			}
+
+			t.negate = true
+
+		case "map":
+			if len(splitted) != 2 || splitted[1] == "" {
+				return tag{}, true, errors.New("invalid map tag")
+			}
+
+			t.mapping = make(map[string]string)
+			for _, entry := range strings.Split(splitted[1], "|") {
+				from, to, found := strings.Cut(entry, ":")
+				if !found {
+					return tag{}, true, fmt.Errorf("invalid map entry: %s", entry)
+				}
+
+				t.mapping[from] = to
+			}
+
+		case "expr":
+			if len(splitted) != 2 {
+				return tag{}, true, errors.New("invalid expr tag")
+			}
+
+			// the expression doesn't depend on anything but the host, so it can be computed right away
+			v, err := evalExpr(splitted[1])
+			if err != nil {
+				return tag{}, true, err
+			}
+
+			t.expr = strconv.Itoa(v)
+
+		case "unit":
+			if field.Type != durationType {
+				return tag{}, true, errors.New("unit can only be used on time.Duration fields")
+			}
+
+			if len(splitted) != 2 {
+				return tag{}, true, errors.New("invalid unit tag")
+			}
+
+			unit, found := durationUnits[splitted[1]]
+			if !found {
+				return tag{}, true, fmt.Errorf("unknown unit: %s", splitted[1])
+			}
+
+			t.unit = unit
+
+		case "in", "store":
+			if len(splitted) != 2 || splitted[1] == "" {
+				return tag{}, true, fmt.Errorf("invalid %s tag", splitted[0])
+			}
+
+			if splitted[0] == "in" {
+				t.inUnit = splitted[1]
+			} else {
+				t.storeUnit = splitted[1]
+			}
+
+		case "json":
+			t.json = true
+
+		case "type":
+			if len(splitted) != 2 || splitted[1] == "" {
+				return tag{}, true, errors.New("invalid type tag")
+			}
+
+			if _, found := lookupType(splitted[1]); !found {
+				return tag{}, true, fmt.Errorf("unknown type: %s", splitted[1])
+			}
+
+			t.typeName = splitted[1]
+
+		case "contiguous":
+			if field.Type.Kind() != reflect.Map {
+				return tag{}, true, errors.New("contiguous can only be used on maps with int keys")
+			}
+
+			switch field.Type.Key().Kind() {
+			case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+				t.contiguous = true
+			default:
+				return tag{}, true, errors.New("contiguous can only be used on maps with int keys")
+			}
+
+		case "schemes":
+			if field.Type != urlType && field.Type != reflect.PointerTo(urlType) {
+				return tag{}, true, errors.New("schemes can only be used on url fields")
+			}
+
+			if len(splitted) != 2 || splitted[1] == "" {
+				return tag{}, true, errors.New("invalid schemes tag")
+			}
+
+			t.schemes = strings.Split(splitted[1], "|")
+
+		case "countprefix":
+			if field.Type.Kind() != reflect.Slice {
+				return tag{}, true, errors.New("countprefix can only be used on slice fields")
+			}
+
+			t.countPrefix = true
+
+		case "errors":
+			if field.Type != errorType {
+				return tag{}, true, errors.New("errors can only be used on error fields")
+			}
+
+			t.collectErrors = true
+
+		case "count":
+			switch field.Type.Kind() {
+			case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			default:
+				return tag{}, true, errors.New("count can only be used on int fields")
+			}
+
+			if len(splitted) != 2 || splitted[1] == "" {
+				return tag{}, true, errors.New("invalid count tag")
+			}
+
+			t.count = splitted[1]
+
+		case "weighted":
+			if field.Type.Kind() != reflect.Slice || !isWeightedType(field.Type.Elem()) {
+				return tag{}, true, errors.New("weighted can only be used on slices of structs with a string and an int field")
+			}
+
+			t.weighted = true
+
+		case "base64":
+			isBytes := field.Type.Kind() == reflect.Slice && field.Type.Elem().Kind() == reflect.Uint8
+			if field.Type.Kind() != reflect.String && !isBytes {
+				return tag{}, true, errors.New("base64 can only be used on string and []byte fields")
+			}
+
+			t.base64 = true
+
+		case "clamp":
+			t.clamp = true
+
+		case "stripcomment":
+			t.stripComment = true
+
+		case "abspath":
+			if field.Type.Kind() != reflect.String {
+				return tag{}, true, errors.New("abspath can only be used on string fields")
+			}
+
+			t.absPath = true
+
+		case "layout", "layouts":
+			if field.Type != timeType {
+				return tag{}, true, fmt.Errorf("%s can only be used on time.Time fields", splitted[0])
+			}
+
+			if len(splitted) != 2 || splitted[1] == "" {
+				return tag{}, true, fmt.Errorf("invalid %s tag", splitted[0])
+			}
+
+			// a single layout may contain the list delimiter itself
+			if splitted[0] == "layout" {
+				t.layouts = []string{splitted[1]}
+			} else {
+				t.layouts = strings.Split(splitted[1], "|")
+			}
+
+		case "indexed":
+			if field.Type.Kind() != reflect.Slice || field.Type.Elem().Kind() != reflect.Struct {
+				return tag{}, true, errors.New("indexed can only be used on slices of structs")
+			}
+
+			if len(splitted) != 2 || strings.Count(splitted[1], "%d") != 1 || strings.Count(splitted[1], "%") != 1 {
+				return tag{}, true, errors.New("invalid indexed tag, expected a pattern with a single %d like SVC_%d_")
+			}
+
+			t.indexed = splitted[1]
+
+		case "consistency":
+			if len(splitted) != 2 || splitted[1] == "" {
+				return tag{}, true, errors.New("invalid consistency tag")
+			}
+
+			t.consistency = splitted[1]
+
+		case "dedup":
+			if field.Type.Kind() != reflect.Slice {
+				return tag{}, true, errors.New("dedup can only be used on slice fields")
+			}
+
+			t.dedup = true
+
+		case "verify":
+			if len(splitted) != 2 {
+				return tag{}, true, errors.New("invalid verify tag")
+			}
+
+			algorithm, key, found := strings.Cut(splitted[1], ":")
+			if !found || key == "" {
+				return tag{}, true, errors.New("invalid verify tag, expected verify=<algorithm>:<key>")
+			}
+
+			if _, found := checksumAlgorithms[algorithm]; !found {
+				return tag{}, true, fmt.Errorf("unknown verify algorithm: %s", algorithm)
+			}
+
+			t.verifyAlgorithm, t.verifyKey = algorithm, key
+
+		case "chain":
+			if len(splitted) != 2 || splitted[1] == "" {
+				return tag{}, true, errors.New("invalid chain tag")
+			}
+
+			stages := strings.Split(splitted[1], "|")
+			for i, stage := range stages {
+				// json decodes into the field, so it has to be the final stage
+				if stage == "json" && i == len(stages)-1 {
+					t.json = true
+					continue
+				}
+
+				if _, found := chainTransforms[stage]; !found {
+					return tag{}, true, fmt.Errorf("unknown chain stage: %s", stage)
+				}
+
+				t.chain = append(t.chain, stage)
+			}
+
+		case "join":
+			if field.Type.Kind() != reflect.String {
+				return tag{}, true, errors.New("join can only be used on string fields")
+			}
+
+			if len(splitted) != 2 || splitted[1] == "" {
+				return tag{}, true, errors.New("invalid join tag")
+			}
+
+			t.join = strings.Split(splitted[1], "|")
+
+		case "joinsep":
 			if len(splitted) != 2 {
+				return tag{}, true, errors.New("invalid joinsep tag")
+			}
+
+			t.joinSeparator = splitted[1]
+
+		case "defaultfrom":
+			if len(splitted) != 2 || splitted[1] == "" {
+				return tag{}, true, errors.New("invalid defaultfrom tag")
+			}
+
+			t.defaultFrom = splitted[1]
+
+		case "skipempty":
+			t.skipEmpty = true
+
+		case "bitflags":
+			switch field.Type.Kind() {
+			case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+				t.bitflags = true
+			default:
+				return tag{}, true, errors.New("bitflags can only be used on int fields")
+			}
+
+		case "relative":
+			if field.Type != timeType {
+				return tag{}, true, errors.New("relative can only be used on time.Time fields")
+			}
+
+			t.relative = true
+
+		case "atomic":
+			if field.Type.Kind() != reflect.Struct {
+				return tag{}, true, errors.New("atomic can only be used on struct fields")
+			}
+
+			t.atomic = true
+
+		case "mergedefault":
+			if field.Type.Kind() != reflect.Map {
+				return tag{}, true, errors.New("mergedefault can only be used on map fields")
+			}
+
+			t.mergeDefault = true
+
+		case "default":
+			// if we have no value we have an invalid tag, any further "=" are part of the value like base64 padding
+			if len(splitted) < 2 {
 				return tag{}, true, errors.New("invalid default tag")
 			}
 
-			defaultVal = splitted[1]
+			t.defaultValue = strings.Join(splitted[1:], "=")
+
+			// sentinels are only resolved when the default is used, but unknown ones are caught early
+			if _, found := defaultSentinels[t.defaultValue]; strings.HasPrefix(t.defaultValue, "@") && !found {
+				return tag{}, true, fmt.Errorf("unknown default sentinel: %s", t.defaultValue)
+			}
+
+		case "format":
+			if len(splitted) != 2 {
+				return tag{}, true, errors.New("invalid format tag")
+			}
+
+			switch splitted[1] {
+			case "rate":
+				if k := field.Type.Kind(); k != reflect.Float32 && k != reflect.Float64 {
+					return tag{}, true, errors.New("format rate can only be used on float fields")
+				}
+
+			case "semver", "email", "url", "uuid", "hostname", "ipv4", "ipv6":
+				if field.Type.Kind() != reflect.String {
+					return tag{}, true, fmt.Errorf("format %s can only be used on string fields", splitted[1])
+				}
+
+			case "range":
+				if !isRangeType(field.Type) {
+					return tag{}, true, errors.New("format range can only be used on structs with int Min and Max fields")
+				}
+
+			default:
+				return tag{}, true, fmt.Errorf("unknown format: %s", splitted[1])
+			}
+
+			t.format = splitted[1]
+
+		case "scale":
+			if len(splitted) != 2 {
+				return tag{}, true, errors.New("invalid scale tag")
+			}
+
+			scale, err := strconv.Atoi(splitted[1])
+			if err != nil || scale < 0 {
+				return tag{}, true, fmt.Errorf("invalid scale tag: %s", splitted[1])
+			}
+
+			t.scale = scale
+
+		case "min", "max":
+			if len(splitted) != 2 {
+				return tag{}, true, fmt.Errorf("invalid %s tag", splitted[0])
+			}
+
+			// on number fields the value itself is bounded instead of the number of entries
+			if isNumberKind(field.Type.Kind()) && field.Type != durationType {
+				bound, err := strconv.ParseFloat(splitted[1], 64)
+				if err != nil || (!isFloatKind(field.Type.Kind()) && bound != math.Trunc(bound)) {
+					return tag{}, true, fmt.Errorf("invalid %s tag: %s", splitted[0], splitted[1])
+				}
+
+				if splitted[0] == "min" {
+					t.minValue = &bound
+				} else {
+					t.maxValue = &bound
+				}
+
+				continue
+			}
+
+			if field.Type.Kind() != reflect.Map {
+				return tag{}, true, fmt.Errorf("%s can only be used on map and number fields", splitted[0])
+			}
+
+			count, err := strconv.Atoi(splitted[1])
+			if err != nil || count < 0 {
+				return tag{}, true, fmt.Errorf("invalid %s tag: %s", splitted[0], splitted[1])
+			}
+
+			if splitted[0] == "min" {
+				t.minCount = count
+			} else {
+				t.maxCount = count
+			}
+
+		case "sources":
+			if len(splitted) != 2 || splitted[1] == "" {
+				return tag{}, true, errors.New("invalid sources tag")
+			}
+
+			for _, name := range strings.Split(splitted[1], "|") {
+				source := Source(name)
+				if source != SourceEnv && source != SourceProvider && source != SourceFile && source != SourceFallback && source != SourceDefault {
+					return tag{}, true, fmt.Errorf("unknown source: %s", name)
+				}
+
+				t.sources = append(t.sources, source)
+			}
+
+		case "split":
+			// the delimiter itself is allowed to contain "="
+			delim := strings.TrimPrefix(trimmed, "split=")
+			if len(splitted) < 2 || delim == "" {
+				return tag{}, true, errors.New("invalid split tag")
+			}
+
+			t.delimiter = delim
+			t.explicitSplit = true
+		}
+	}
+
+	// slices of structs are indexed under their own name if they aren't decoded in any other way, like SERVERS_0_HOST
+	if t.indexed == "" && !t.json && !t.weighted && t.typeName == "" && t.format != "range" && isIndexableSlice(field.Type, config) {
+		t.indexed = strings.ReplaceAll(t.name, "%", "%%") + "_%d_"
+	}
+
+	if t.clamp && t.minValue == nil && t.maxValue == nil {
+		return tag{}, true, errors.New("clamp can only be used together with min or max on number fields")
+	}
+
+	// numbers are converted from the unit they are written in to the unit they are stored in
+	if t.inUnit != "" || t.storeUnit != "" {
+		factor, err := conversionFactor(field.Type, t.inUnit, t.storeUnit)
+		if err != nil {
+			return tag{}, true, err
+		}
+
+		t.conversion = factor
+	}
+
+	// a computed default is used just like a regular one
+	if t.expr != "" {
+		if t.defaultValue != "" {
+			return tag{}, true, errors.New("expr and default can't be used together")
+		}
+
+		t.defaultValue = t.expr
+	}
+
+	return t, true, nil
+}
+
+// Checks if the type is a struct with exactly two fields, a string name followed by an integer weight.
+func isWeightedType(t reflect.Type) bool {
+	if t.Kind() != reflect.Struct || t.NumField() != 2 {
+		return false
+	}
+
+	switch t.Field(1).Type.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return t.Field(0).Type.Kind() == reflect.String && t.Field(0).IsExported() && t.Field(1).IsExported()
+	default:
+		return false
+	}
+}
+
+// Checks if the type is a struct with int Min and Max fields.
+func isRangeType(t reflect.Type) bool {
+	if t.Kind() != reflect.Struct {
+		return false
+	}
+
+	min, hasMin := t.FieldByName("Min")
+	max, hasMax := t.FieldByName("Max")
+	return hasMin && hasMax && min.Type.Kind() == reflect.Int && max.Type.Kind() == reflect.Int
+}
+
+// Parses a range like "8000-8100" into the Min and Max fields of the struct.
+// Negative bounds are not supported since "-" is the separator.
+func setRange(f reflect.Value, val string) error {
+	lower, upper, found := strings.Cut(val, "-")
+	if !found {
+		return fmt.Errorf("invalid range \"%s\": expected format <min>-<max>", val)
+	}
+
+	min, err := strconv.Atoi(lower)
+	if err != nil {
+		return fmt.Errorf("invalid range \"%s\": %w", val, err)
+	}
+
+	max, err := strconv.Atoi(upper)
+	if err != nil {
+		return fmt.Errorf("invalid range \"%s\": %w", val, err)
+	}
+
+	if min > max {
+		return fmt.Errorf("invalid range \"%s\": min is greater than max", val)
+	}
+
+	f.FieldByName("Min").SetInt(int64(min))
+	f.FieldByName("Max").SetInt(int64(max))
+	return nil
+}
+
+// Parses the case-insensitive full or three-letter name of a weekday or month, or its number within [min, max].
+func parseCalendarName(val string, min int, max int, name func(int) string) (int, error) {
+	for i := min; i <= max; i++ {
+		n := name(i)
+		if strings.EqualFold(val, n) || strings.EqualFold(val, n[:3]) {
+			return i, nil
+		}
+	}
+
+	n, err := strconv.Atoi(val)
+	if err != nil {
+		return 0, errors.New("unknown name")
+	}
+
+	if n < min || n > max {
+		return 0, fmt.Errorf("number must be between %d and %d", min, max)
+	}
+
+	return n, nil
+}
+
+// The hash algorithms that can be used by the verify option
+var checksumAlgorithms = map[string]func() hash.Hash{
+	"sha256": sha256.New,
+	"sha512": sha512.New,
+}
+
+// Computes the checksum of the value and compares it to the hex encoded checksum in the verify variable.
+func verifyChecksum(val string, t tag, config *LoadConfig) error {
+	key := lookupKey(tag{name: t.verifyKey}, config)
+
+	expected, _, err := fetchFieldValue(key, tag{name: t.verifyKey, required: true}, config)
+	if err != nil {
+		return fmt.Errorf("failed to read checksum from \"%s\": %w", reportedKey(key, config), err)
+	}
+
+	h := checksumAlgorithms[t.verifyAlgorithm]()
+	h.Write([]byte(val))
+
+	actual := hex.EncodeToString(h.Sum(nil))
+	if !strings.EqualFold(actual, strings.TrimSpace(expected)) {
+		return fmt.Errorf("%s checksum of value does not match \"%s\"", t.verifyAlgorithm, reportedKey(key, config))
+	}
+
+	return nil
+}
+
+// The transforms that can be used as stages of the chain option, each turning a string into another one
+var chainTransforms = map[string]func(string) (string, error){
+	"trim": func(val string) (string, error) {
+		return strings.TrimSpace(val), nil
+	},
+	"lower": func(val string) (string, error) {
+		return strings.ToLower(val), nil
+	},
+	"upper": func(val string) (string, error) {
+		return strings.ToUpper(val), nil
+	},
+	"base64": func(val string) (string, error) {
+		b, err := base64.StdEncoding.DecodeString(val)
+		return string(b), err
+	},
+	"base64url": func(val string) (string, error) {
+		b, err := base64.URLEncoding.DecodeString(val)
+		return string(b), err
+	},
+	"hex": func(val string) (string, error) {
+		b, err := hex.DecodeString(val)
+		return string(b), err
+	},
+}
+
+// Applies the transforms of a chain to the value in order.
+func applyChain(val string, chain []string) (string, error) {
+	for _, stage := range chain {
+		var err error
+		val, err = chainTransforms[stage](val)
+		if err != nil {
+			return "", fmt.Errorf("chain stage %s failed: %w", stage, err)
 		}
 	}
 
-	return tag{
-		name:         parts[0],
-		required:     required,
-		defaultValue: defaultVal,
-	}, true, nil
+	return val, nil
+}
+
+// Parses a rate like "100/s" or "60/m" into events per second.
+func parseRate(val string) (float64, error) {
+	amount, unit, found := strings.Cut(val, "/")
+	if !found {
+		return 0, fmt.Errorf("invalid rate \"%s\": expected format <amount>/<unit>", val)
+	}
+
+	n, err := strconv.ParseFloat(amount, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid rate \"%s\": %w", val, err)
+	}
+
+	var per time.Duration
+	switch unit {
+	case "ms":
+		per = time.Millisecond
+	case "s":
+		per = time.Second
+	case "m":
+		per = time.Minute
+	case "h":
+		per = time.Hour
+	default:
+		return 0, fmt.Errorf("invalid rate \"%s\": unknown unit \"%s\"", val, unit)
+	}
+
+	return n / per.Seconds(), nil
 }
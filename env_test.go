@@ -1,8 +1,26 @@
 package minienv_test
 
 import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math/big"
+	"net"
+	"net/mail"
+	"net/url"
 	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"strconv"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/yannickalex07/minienv"
@@ -211,7 +229,7 @@ func TestLoadWithMissingNestedValue(t *testing.T) {
 func TestLoadWithUnsupportedType(t *testing.T) {
 	// Arrange
 	type S struct {
-		Value map[string]string `env:"TEST_VALUE"`
+		Value complex128 `env:"TEST_VALUE"`
 	}
 
 	os.Setenv("TEST_VALUE", "test-value")
@@ -322,6 +340,64 @@ func TestLoadWithDefaultInt(t *testing.T) {
 	assert.Equal(t, 5, s.Value)
 }
 
+func TestLoadWithDefaultSentinels(t *testing.T) {
+	// Arrange
+	type S struct {
+		Host string `env:"TEST_HOST,default=@hostname"`
+		PID  int    `env:"TEST_PID,default=@pid"`
+		Dir  string `env:"TEST_DIR,default=@pwd"`
+	}
+
+	hostname, _ := os.Hostname()
+	wd, _ := os.Getwd()
+
+	// Act
+	var s S
+	err := minienv.Load(&s)
+
+	// Assert
+	assert.Nil(t, err)
+	assert.Equal(t, hostname, s.Host)
+	assert.Equal(t, os.Getpid(), s.PID)
+	assert.Equal(t, wd, s.Dir)
+}
+
+func TestLoadWithDefaultSentinelOverriddenByEnv(t *testing.T) {
+	// Arrange
+	type S struct {
+		Host string `env:"TEST_HOST,default=@hostname"`
+	}
+
+	os.Setenv("TEST_HOST", "my-host")
+	defer os.Unsetenv("TEST_HOST")
+
+	// Act
+	var s S
+	err := minienv.Load(&s)
+
+	// Assert
+	assert.Nil(t, err)
+	assert.Equal(t, "my-host", s.Host)
+}
+
+func TestLoadWithUnknownDefaultSentinel(t *testing.T) {
+	// Arrange
+	type S struct {
+		Value string `env:"TEST_VALUE,default=@user"`
+	}
+
+	// Act
+	var s S
+	err := minienv.Load(&s)
+
+	// Assert
+	assert.Error(t, err)
+
+	tagParseErr := err.(minienv.LoadError)
+	assert.Equal(t, "Value", tagParseErr.Field)
+	assert.ErrorContains(t, tagParseErr, "unknown default sentinel: @user")
+}
+
 func TestLoadWithDefaultMissingValue(t *testing.T) {
 	// Arrange
 	type S struct {
@@ -357,3 +433,4325 @@ func TestLoadWithUnsettableField(t *testing.T) {
 	assert.Error(t, err)
 	assert.ErrorContains(t, err, "not valid or cannot be set")
 }
+
+func TestLoadWithDuration(t *testing.T) {
+	// Arrange
+	type S struct {
+		Value time.Duration `env:"TEST_VALUE"`
+	}
+
+	os.Setenv("TEST_VALUE", "1h30m")
+	defer os.Unsetenv("TEST_VALUE")
+
+	// Act
+	var s S
+	err := minienv.Load(&s)
+
+	// Assert
+	assert.Nil(t, err)
+	assert.Equal(t, 90*time.Minute, s.Value)
+}
+
+func TestLoadWithDurationDefault(t *testing.T) {
+	// Arrange
+	type S struct {
+		Value time.Duration `env:"HTTP_TIMEOUT,default=5m"`
+	}
+
+	// Act
+	var s S
+	err := minienv.Load(&s)
+
+	// Assert
+	assert.Nil(t, err)
+	assert.Equal(t, 5*time.Minute, s.Value)
+}
+
+func TestLoadWithInvalidDuration(t *testing.T) {
+	for _, value := range []string{"30", "30x", "soon"} {
+		t.Run(value, func(t *testing.T) {
+			// Arrange
+			type S struct {
+				Value time.Duration `env:"HTTP_TIMEOUT"`
+			}
+
+			os.Setenv("HTTP_TIMEOUT", value)
+			defer os.Unsetenv("HTTP_TIMEOUT")
+
+			// Act
+			var s S
+			err := minienv.Load(&s)
+
+			// Assert
+			assert.Error(t, err)
+
+			conversionErr := err.(minienv.LoadError)
+			assert.Equal(t, "Value", conversionErr.Field)
+			assert.ErrorContains(t, conversionErr, fmt.Sprintf("invalid duration \"%s\"", value))
+		})
+	}
+}
+
+func TestLoadWithDurationSlice(t *testing.T) {
+	// Arrange
+	type S struct {
+		Value []time.Duration `env:"TEST_VALUE"`
+	}
+
+	os.Setenv("TEST_VALUE", "1h30m|45s|2m")
+	defer os.Unsetenv("TEST_VALUE")
+
+	// Act
+	var s S
+	err := minienv.Load(&s)
+
+	// Assert
+	assert.Nil(t, err)
+	assert.Equal(t, []time.Duration{90 * time.Minute, 45 * time.Second, 2 * time.Minute}, s.Value)
+}
+
+func TestLoadWithDurationSliceAndMultiCharDelimiter(t *testing.T) {
+	// Arrange
+	type S struct {
+		Value []time.Duration `env:"TEST_VALUE,split=ms"`
+	}
+
+	// the delimiter contains unit letters, so it must not be confused with them
+	os.Setenv("TEST_VALUE", "1h30mms45sms2m")
+	defer os.Unsetenv("TEST_VALUE")
+
+	// Act
+	var s S
+	err := minienv.Load(&s)
+
+	// Assert
+	assert.Nil(t, err)
+	assert.Equal(t, []time.Duration{90 * time.Minute, 45 * time.Second, 2 * time.Minute}, s.Value)
+}
+
+func TestLoadWithInvalidDurationSliceElement(t *testing.T) {
+	// Arrange
+	type S struct {
+		Value []time.Duration `env:"TEST_VALUE"`
+	}
+
+	os.Setenv("TEST_VALUE", "1h30m|1h30x|2m")
+	defer os.Unsetenv("TEST_VALUE")
+
+	// Act
+	var s S
+	err := minienv.Load(&s)
+
+	// Assert
+	assert.Error(t, err)
+
+	conversionErr := err.(minienv.LoadError)
+	assert.Equal(t, "Value", conversionErr.Field)
+	assert.ErrorContains(t, conversionErr, "index 1")
+}
+
+func TestLoadWithEmptySplitTag(t *testing.T) {
+	// Arrange
+	type S struct {
+		Value []string `env:"TEST_VALUE,split="`
+	}
+
+	// Act
+	var s S
+	err := minienv.Load(&s)
+
+	// Assert
+	assert.Error(t, err)
+	assert.ErrorContains(t, err, "invalid split tag")
+}
+
+func TestLoadWithNegate(t *testing.T) {
+	// Arrange
+	type S struct {
+		EnableCache bool `env:"DISABLE_CACHE,negate"`
+		EnableLogs  bool `env:"DISABLE_LOGS,negate"`
+	}
+
+	os.Setenv("DISABLE_CACHE", "true")
+	defer os.Unsetenv("DISABLE_CACHE")
+
+	os.Setenv("DISABLE_LOGS", "false")
+	defer os.Unsetenv("DISABLE_LOGS")
+
+	// Act
+	var s S
+	err := minienv.Load(&s)
+
+	// Assert
+	assert.Nil(t, err)
+	assert.Equal(t, false, s.EnableCache)
+	assert.Equal(t, true, s.EnableLogs)
+}
+
+func TestLoadWithNegateOnNonBool(t *testing.T) {
+	// Arrange
+	type S struct {
+		Value string `env:"TEST_VALUE,negate"`
+	}
+
+	os.Setenv("TEST_VALUE", "true")
+	defer os.Unsetenv("TEST_VALUE")
+
+	// Act
+	var s S
+	err := minienv.Load(&s)
+
+	// Assert
+	assert.Error(t, err)
+
+	tagParseErr := err.(minienv.LoadError)
+	assert.Equal(t, "Value", tagParseErr.Field)
+	assert.ErrorContains(t, tagParseErr, "negate can only be used on bool fields")
+}
+
+func TestLoadWithEmptyInterface(t *testing.T) {
+	// Arrange
+	type S struct {
+		Any   any         `env:"TEST_ANY"`
+		Iface interface{} `env:"TEST_IFACE"`
+	}
+
+	os.Setenv("TEST_ANY", "123")
+	defer os.Unsetenv("TEST_ANY")
+
+	os.Setenv("TEST_IFACE", "true")
+	defer os.Unsetenv("TEST_IFACE")
+
+	// Act
+	var s S
+	err := minienv.Load(&s)
+
+	// Assert
+	assert.Nil(t, err)
+	assert.Equal(t, "123", s.Any)
+	assert.Equal(t, "true", s.Iface)
+}
+
+func TestLoadWithNonEmptyInterface(t *testing.T) {
+	// Arrange
+	type S struct {
+		Value fmt.Stringer `env:"TEST_VALUE"`
+	}
+
+	os.Setenv("TEST_VALUE", "test-value")
+	defer os.Unsetenv("TEST_VALUE")
+
+	// Act
+	var s S
+	err := minienv.Load(&s)
+
+	// Assert
+	assert.Error(t, err)
+
+	conversionErr := err.(minienv.LoadError)
+	assert.Equal(t, "Value", conversionErr.Field)
+	assert.ErrorContains(t, conversionErr, "unsupported type: fmt.Stringer")
+}
+
+func TestLoadWithRateFormat(t *testing.T) {
+	// Arrange
+	type S struct {
+		PerSecond float64 `env:"PER_SECOND,format=rate"`
+		PerMinute float64 `env:"PER_MINUTE,format=rate"`
+	}
+
+	os.Setenv("PER_SECOND", "100/s")
+	defer os.Unsetenv("PER_SECOND")
+
+	os.Setenv("PER_MINUTE", "60/m")
+	defer os.Unsetenv("PER_MINUTE")
+
+	// Act
+	var s S
+	err := minienv.Load(&s)
+
+	// Assert
+	assert.Nil(t, err)
+	assert.Equal(t, 100.0, s.PerSecond)
+	assert.Equal(t, 1.0, s.PerMinute)
+}
+
+func TestLoadWithInvalidRate(t *testing.T) {
+	// Arrange
+	type S struct {
+		Value float64 `env:"TEST_VALUE,format=rate"`
+	}
+
+	os.Setenv("TEST_VALUE", "100/d")
+	defer os.Unsetenv("TEST_VALUE")
+
+	// Act
+	var s S
+	err := minienv.Load(&s)
+
+	// Assert
+	assert.Error(t, err)
+
+	conversionErr := err.(minienv.LoadError)
+	assert.Equal(t, "Value", conversionErr.Field)
+	assert.ErrorContains(t, conversionErr, "invalid rate \"100/d\": unknown unit \"d\"")
+}
+
+func TestLoadWithUnknownFormat(t *testing.T) {
+	// Arrange
+	type S struct {
+		Value float64 `env:"TEST_VALUE,format=unknown"`
+	}
+
+	// Act
+	var s S
+	err := minienv.Load(&s)
+
+	// Assert
+	assert.Error(t, err)
+	assert.ErrorContains(t, err, "unknown format: unknown")
+}
+
+// A minimal fixed-precision type that stores amounts in cents
+type Money struct {
+	Cents int64
+}
+
+func (m *Money) UnmarshalText(text []byte) error {
+	units, cents, _ := strings.Cut(string(text), ".")
+	for len(cents) < 2 {
+		cents += "0"
+	}
+
+	v, err := strconv.ParseInt(units+cents, 10, 64)
+	if err != nil {
+		return err
+	}
+
+	m.Cents = v
+	return nil
+}
+
+func TestLoadWithTextUnmarshaler(t *testing.T) {
+	// Arrange
+	type S struct {
+		Price  Money   `env:"PRICE"`
+		Prices []Money `env:"PRICES"`
+	}
+
+	os.Setenv("PRICE", "12.34")
+	defer os.Unsetenv("PRICE")
+
+	os.Setenv("PRICES", "1.5|2")
+	defer os.Unsetenv("PRICES")
+
+	// Act
+	var s S
+	err := minienv.Load(&s)
+
+	// Assert
+	assert.Nil(t, err)
+	assert.Equal(t, Money{Cents: 1234}, s.Price)
+	assert.Equal(t, []Money{{Cents: 150}, {Cents: 200}}, s.Prices)
+}
+
+func TestLoadWithTextUnmarshalerFromStandardLibrary(t *testing.T) {
+	// Arrange
+	type S struct {
+		IP  net.IP    `env:"TEST_IP"`
+		IPs []net.IP  `env:"TEST_IPS"`
+		Big big.Float `env:"TEST_BIG"`
+	}
+
+	os.Setenv("TEST_IP", "10.0.0.1")
+	defer os.Unsetenv("TEST_IP")
+
+	os.Setenv("TEST_IPS", "10.0.0.2|::1")
+	defer os.Unsetenv("TEST_IPS")
+
+	os.Setenv("TEST_BIG", "1.5")
+	defer os.Unsetenv("TEST_BIG")
+
+	// Act
+	var s S
+	err := minienv.Load(&s)
+
+	// Assert
+	assert.Nil(t, err)
+	assert.Equal(t, "10.0.0.1", s.IP.String())
+	assert.Len(t, s.IPs, 2)
+	assert.Equal(t, "::1", s.IPs[1].String())
+	assert.Equal(t, "1.5", s.Big.String())
+}
+
+func TestLoadWithFailingTextUnmarshaler(t *testing.T) {
+	// Arrange
+	type S struct {
+		IP net.IP `env:"TEST_IP"`
+	}
+
+	os.Setenv("TEST_IP", "not-an-ip")
+	defer os.Unsetenv("TEST_IP")
+
+	// Act
+	var s S
+	err := minienv.Load(&s)
+
+	// Assert
+	assert.Error(t, err)
+
+	loadErr := err.(minienv.LoadError)
+	assert.Equal(t, "IP", loadErr.Field)
+	assert.ErrorContains(t, loadErr, "failed to unmarshal \"not-an-ip\" into net.IP")
+}
+
+func TestLoadWithScale(t *testing.T) {
+	// Arrange
+	type S struct {
+		Price  Money   `env:"PRICE,scale=2"`
+		Amount float64 `env:"AMOUNT,scale=2"`
+		Text   string  `env:"TEXT,scale=3"`
+	}
+
+	os.Setenv("PRICE", "12.34")
+	defer os.Unsetenv("PRICE")
+
+	os.Setenv("AMOUNT", "10")
+	defer os.Unsetenv("AMOUNT")
+
+	os.Setenv("TEXT", "0.125")
+	defer os.Unsetenv("TEXT")
+
+	// Act
+	var s S
+	err := minienv.Load(&s)
+
+	// Assert
+	assert.Nil(t, err)
+	assert.Equal(t, Money{Cents: 1234}, s.Price)
+	assert.Equal(t, 10.0, s.Amount)
+	assert.Equal(t, "0.125", s.Text)
+}
+
+func TestLoadWithExceededScale(t *testing.T) {
+	// Arrange
+	type S struct {
+		Value float64 `env:"TEST_VALUE,scale=2"`
+	}
+
+	os.Setenv("TEST_VALUE", "12.345")
+	defer os.Unsetenv("TEST_VALUE")
+
+	// Act
+	var s S
+	err := minienv.Load(&s)
+
+	// Assert
+	assert.Error(t, err)
+
+	scaleErr := err.(minienv.LoadError)
+	assert.Equal(t, "Value", scaleErr.Field)
+	assert.ErrorContains(t, scaleErr, "value \"12.345\" has 3 decimal places, but at most 2 are allowed")
+}
+
+func TestLoadWithSources(t *testing.T) {
+	// Arrange
+	type S struct {
+		Secret   string `env:"SECRET,sources=file|env"`
+		Global   string `env:"GLOBAL"`
+		EnvOnly  string `env:"ENV_ONLY,sources=env|default,default=def"`
+		Disabled string `env:"DISABLED,sources=env,optional"`
+	}
+
+	os.Setenv("SECRET", "from-env")
+	defer os.Unsetenv("SECRET")
+
+	os.Setenv("GLOBAL", "from-env")
+	defer os.Unsetenv("GLOBAL")
+
+	filename := "test.env"
+
+	CreateFile(t, filename, []string{
+		"SECRET=from-file",
+		"GLOBAL=from-file",
+		"ENV_ONLY=from-file",
+		"DISABLED=from-file",
+	})
+	defer RemoveFile(t, filename)
+
+	// Act
+	var s S
+	err := minienv.Load(&s, minienv.WithFile(true, filename))
+
+	// Assert
+	assert.Nil(t, err)
+	assert.Equal(t, "from-file", s.Secret)
+	assert.Equal(t, "from-env", s.Global)
+	assert.Equal(t, "def", s.EnvOnly)
+	assert.Equal(t, "", s.Disabled)
+}
+
+func TestLoadWithSourcesAndMissingValue(t *testing.T) {
+	// Arrange
+	type S struct {
+		Value string `env:"TEST_VALUE,sources=file"`
+	}
+
+	os.Setenv("TEST_VALUE", "from-env")
+	defer os.Unsetenv("TEST_VALUE")
+
+	// Act
+	var s S
+	err := minienv.Load(&s)
+
+	// Assert
+	assert.Error(t, err)
+
+	missingErr := err.(minienv.LoadError)
+	assert.Equal(t, "Value", missingErr.Field)
+	assert.ErrorContains(t, missingErr, "required field has no value in any of its sources")
+}
+
+func TestLoadWithUnknownSource(t *testing.T) {
+	// Arrange
+	type S struct {
+		Value string `env:"TEST_VALUE,sources=env|vault"`
+	}
+
+	// Act
+	var s S
+	err := minienv.Load(&s)
+
+	// Assert
+	assert.Error(t, err)
+	assert.ErrorContains(t, err, "unknown source: vault")
+}
+
+func TestLoadWithSlogLevel(t *testing.T) {
+	tests := map[string]slog.Level{
+		"debug": slog.LevelDebug,
+		"INFO":  slog.LevelInfo,
+		"Warn":  slog.LevelWarn,
+		"error": slog.LevelError,
+	}
+
+	for input, expected := range tests {
+		t.Run(input, func(t *testing.T) {
+			// Arrange
+			type S struct {
+				Value slog.Level `env:"LOG_LEVEL"`
+			}
+
+			os.Setenv("LOG_LEVEL", input)
+			defer os.Unsetenv("LOG_LEVEL")
+
+			// Act
+			var s S
+			err := minienv.Load(&s)
+
+			// Assert
+			assert.Nil(t, err)
+			assert.Equal(t, expected, s.Value)
+		})
+	}
+}
+
+func TestLoadWithInvalidSlogLevel(t *testing.T) {
+	// Arrange
+	type S struct {
+		Value slog.Level `env:"LOG_LEVEL"`
+	}
+
+	os.Setenv("LOG_LEVEL", "verbose")
+	defer os.Unsetenv("LOG_LEVEL")
+
+	// Act
+	var s S
+	err := minienv.Load(&s)
+
+	// Assert
+	assert.Error(t, err)
+
+	conversionErr := err.(minienv.LoadError)
+	assert.Equal(t, "Value", conversionErr.Field)
+	assert.ErrorContains(t, conversionErr, "unknown name")
+}
+
+func TestLoadWithMap(t *testing.T) {
+	// Arrange
+	type S struct {
+		Value map[string]int `env:"TEST_VALUE"`
+	}
+
+	os.Setenv("TEST_VALUE", "a:1|b:2")
+	defer os.Unsetenv("TEST_VALUE")
+
+	// Act
+	var s S
+	err := minienv.Load(&s)
+
+	// Assert
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]int{"a": 1, "b": 2}, s.Value)
+}
+
+func TestLoadWithInvalidMapEntry(t *testing.T) {
+	// Arrange
+	type S struct {
+		Value map[string]int `env:"TEST_VALUE"`
+	}
+
+	os.Setenv("TEST_VALUE", "a:1|b")
+	defer os.Unsetenv("TEST_VALUE")
+
+	// Act
+	var s S
+	err := minienv.Load(&s)
+
+	// Assert
+	assert.Error(t, err)
+
+	conversionErr := err.(minienv.LoadError)
+	assert.Equal(t, "Value", conversionErr.Field)
+	assert.ErrorContains(t, conversionErr, "invalid map entry at index 1")
+}
+
+func TestLoadWithMapKeyValueSeparator(t *testing.T) {
+	// Arrange
+	type S struct {
+		Schedule map[string]string `env:"SCHEDULE,kvsep=="`
+		Arrow    map[string]int    `env:"ARROW,split=;,kvsep==>"`
+		URLs     map[string]string `env:"URLS"`
+	}
+
+	os.Setenv("SCHEDULE", "08:00=open|18:00=closed")
+	defer os.Unsetenv("SCHEDULE")
+
+	os.Setenv("ARROW", "a=>1;b=>2")
+	defer os.Unsetenv("ARROW")
+
+	os.Setenv("URLS", "api:https://api.example.com")
+	defer os.Unsetenv("URLS")
+
+	// Act
+	var s S
+	err := minienv.Load(&s)
+
+	// Assert
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]string{"08:00": "open", "18:00": "closed"}, s.Schedule)
+	assert.Equal(t, map[string]int{"a": 1, "b": 2}, s.Arrow)
+	assert.Equal(t, map[string]string{"api": "https://api.example.com"}, s.URLs)
+}
+
+func TestLoadWithMissingMapKeyValueSeparator(t *testing.T) {
+	// Arrange
+	type S struct {
+		Value map[string]int `env:"TEST_VALUE,kvsep==>"`
+	}
+
+	os.Setenv("TEST_VALUE", "a=>1|b:2")
+	defer os.Unsetenv("TEST_VALUE")
+
+	// Act
+	var s S
+	err := minienv.Load(&s)
+
+	// Assert
+	assert.Error(t, err)
+	assert.ErrorContains(t, err, "invalid map entry at index 1: missing \"=>\"")
+}
+
+func TestLoadWithKeyValueSeparatorOnNonMap(t *testing.T) {
+	// Arrange
+	type S struct {
+		Value string `env:"TEST_VALUE,kvsep=="`
+	}
+
+	// Act
+	var s S
+	err := minienv.Load(&s)
+
+	// Assert
+	assert.Error(t, err)
+	assert.ErrorContains(t, err, "kvsep can only be used on map fields")
+}
+
+func TestLoadWithMapKeyTransform(t *testing.T) {
+	tests := map[string]map[string]int{
+		"none":  {"Free": 10, "PRO": 100, "pro": 200},
+		"lower": {"free": 10, "pro": 200},
+		"upper": {"FREE": 10, "PRO": 200},
+	}
+
+	for transform, expected := range tests {
+		t.Run(transform, func(t *testing.T) {
+			// Arrange
+			field := reflect.StructField{
+				Name: "Value",
+				Type: reflect.TypeOf(map[string]int{}),
+				Tag:  reflect.StructTag(fmt.Sprintf(`env:"TEST_VALUE,keytransform=%s"`, transform)),
+			}
+
+			os.Setenv("TEST_VALUE", "Free:10|PRO:100|pro:200")
+			defer os.Unsetenv("TEST_VALUE")
+
+			obj := reflect.New(reflect.StructOf([]reflect.StructField{field}))
+
+			// Act
+			err := minienv.Load(obj.Interface())
+
+			// Assert
+			assert.Nil(t, err)
+			assert.Equal(t, expected, obj.Elem().Field(0).Interface())
+		})
+	}
+}
+
+func TestLoadWithInvalidMapKeyTransform(t *testing.T) {
+	// Arrange
+	type S struct {
+		Value map[string]int `env:"TEST_VALUE,keytransform=title"`
+	}
+
+	// Act
+	var s S
+	err := minienv.Load(&s)
+
+	// Assert
+	assert.Error(t, err)
+	assert.ErrorContains(t, err, "unknown keytransform: title")
+}
+
+func TestLoadWithMapCount(t *testing.T) {
+	tests := map[string]struct {
+		value string
+		err   string
+	}{
+		"under":      {value: "a:1", err: "map has 1 entries, but at least 2 are required"},
+		"at minimum": {value: "a:1|b:2"},
+		"at maximum": {value: "a:1|b:2|c:3"},
+		"over":       {value: "a:1|b:2|c:3|d:4", err: "map has 4 entries, but at most 3 are allowed"},
+		"duplicates": {value: "a:1|a:2|a:3|b:4"},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			// Arrange
+			type S struct {
+				Tags map[string]string `env:"TAGS,min=2,max=3"`
+			}
+
+			os.Setenv("TAGS", test.value)
+			defer os.Unsetenv("TAGS")
+
+			// Act
+			var s S
+			err := minienv.Load(&s)
+
+			// Assert
+			if test.err == "" {
+				assert.Nil(t, err)
+				return
+			}
+
+			assert.Error(t, err)
+
+			countErr := err.(minienv.LoadError)
+			assert.Equal(t, "Tags", countErr.Field)
+			assert.ErrorContains(t, countErr, test.err)
+		})
+	}
+}
+
+func TestLoadWithMapCountOnInvalidType(t *testing.T) {
+	// Arrange
+	type S struct {
+		Value string `env:"TEST_VALUE,max=10"`
+	}
+
+	// Act
+	var s S
+	err := minienv.Load(&s)
+
+	// Assert
+	assert.Error(t, err)
+	assert.ErrorContains(t, err, "max can only be used on map and number fields")
+}
+
+func TestLoadWithMapDefault(t *testing.T) {
+	// Arrange
+	type S struct {
+		Replaced map[string]int `env:"REPLACED,default=a:1|b:2"`
+		Merged   map[string]int `env:"MERGED,mergedefault,default=a:1|b:2"`
+		Default  map[string]int `env:"DEFAULT,mergedefault,default=a:1|b:2"`
+	}
+
+	os.Setenv("REPLACED", "b:3|c:4")
+	defer os.Unsetenv("REPLACED")
+
+	os.Setenv("MERGED", "b:3|c:4")
+	defer os.Unsetenv("MERGED")
+
+	// Act
+	var s S
+	err := minienv.Load(&s)
+
+	// Assert
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]int{"b": 3, "c": 4}, s.Replaced)
+	assert.Equal(t, map[string]int{"a": 1, "b": 3, "c": 4}, s.Merged)
+	assert.Equal(t, map[string]int{"a": 1, "b": 2}, s.Default)
+}
+
+func TestLoadWithMapOnlyDefault(t *testing.T) {
+	// Arrange
+	type S struct {
+		Value      map[string]string `env:"TEST_VALUE,default=a:1|b:2"`
+		Duplicates map[string]int    `env:"TEST_DUPLICATES,default=a:1|b:2|a:3"`
+	}
+
+	// Act
+	var s S
+	err := minienv.Load(&s)
+
+	// Assert
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]string{"a": "1", "b": "2"}, s.Value)
+	assert.Equal(t, map[string]int{"a": 3, "b": 2}, s.Duplicates)
+}
+
+func TestLoadWithInvalidMapDefault(t *testing.T) {
+	// Arrange
+	type S struct {
+		Value map[string]int `env:"TEST_VALUE,default=a:1|b"`
+	}
+
+	// Act
+	var s S
+	err := minienv.Load(&s)
+
+	// Assert
+	assert.Error(t, err)
+
+	conversionErr := err.(minienv.LoadError)
+	assert.Equal(t, "Value", conversionErr.Field)
+	assert.ErrorContains(t, conversionErr, "invalid map entry at index 1")
+}
+
+func TestLoadWithMergeDefaultOnNonMap(t *testing.T) {
+	// Arrange
+	type S struct {
+		Value string `env:"TEST_VALUE,mergedefault"`
+	}
+
+	// Act
+	var s S
+	err := minienv.Load(&s)
+
+	// Assert
+	assert.Error(t, err)
+	assert.ErrorContains(t, err, "mergedefault can only be used on map fields")
+}
+
+func TestLoadWithAtomicStruct(t *testing.T) {
+	// Arrange
+	type TLS struct {
+		Cert string `env:"TLS_CERT"`
+		Key  string `env:"TLS_KEY"`
+		CA   string `env:"TLS_CA,optional"`
+	}
+
+	type S struct {
+		TLS TLS `env:",atomic"`
+	}
+
+	os.Setenv("TLS_CERT", "cert")
+	defer os.Unsetenv("TLS_CERT")
+
+	os.Setenv("TLS_KEY", "key")
+	defer os.Unsetenv("TLS_KEY")
+
+	// Act
+	var s S
+	err := minienv.Load(&s)
+
+	// Assert
+	assert.Nil(t, err)
+	assert.Equal(t, TLS{Cert: "cert", Key: "key"}, s.TLS)
+}
+
+func TestLoadWithAtomicStructAndNoValues(t *testing.T) {
+	// Arrange
+	type TLS struct {
+		Cert string `env:"TLS_CERT"`
+		Key  string `env:"TLS_KEY"`
+	}
+
+	type S struct {
+		TLS TLS `env:",atomic"`
+	}
+
+	// Act
+	var s S
+	err := minienv.Load(&s)
+
+	// Assert
+	assert.Nil(t, err)
+	assert.Equal(t, TLS{}, s.TLS)
+}
+
+func TestLoadWithPartialAtomicStruct(t *testing.T) {
+	// Arrange
+	type TLS struct {
+		Cert string `env:"TLS_CERT"`
+		Key  string `env:"TLS_KEY"`
+		CA   string `env:"TLS_CA"`
+	}
+
+	type S struct {
+		TLS TLS `env:",atomic"`
+	}
+
+	os.Setenv("TLS_CERT", "cert")
+	defer os.Unsetenv("TLS_CERT")
+
+	// Act
+	var s S
+	err := minienv.Load(&s)
+
+	// Assert
+	assert.Error(t, err)
+
+	atomicErr := err.(minienv.LoadError)
+	assert.Equal(t, "TLS", atomicErr.Field)
+	assert.ErrorContains(t, atomicErr, "struct is only partially configured, missing: TLS_KEY, TLS_CA")
+}
+
+func TestLoadWithMailAddress(t *testing.T) {
+	// Arrange
+	type S struct {
+		From       mail.Address    `env:"FROM"`
+		ReplyTo    *mail.Address   `env:"REPLY_TO"`
+		Recipients []*mail.Address `env:"RECIPIENTS"`
+	}
+
+	os.Setenv("FROM", "Alice <alice@example.com>")
+	defer os.Unsetenv("FROM")
+
+	os.Setenv("REPLY_TO", "support@example.com")
+	defer os.Unsetenv("REPLY_TO")
+
+	os.Setenv("RECIPIENTS", "Bob <bob@example.com>|carol@example.com")
+	defer os.Unsetenv("RECIPIENTS")
+
+	// Act
+	var s S
+	err := minienv.Load(&s)
+
+	// Assert
+	assert.Nil(t, err)
+	assert.Equal(t, mail.Address{Name: "Alice", Address: "alice@example.com"}, s.From)
+	assert.Equal(t, &mail.Address{Address: "support@example.com"}, s.ReplyTo)
+	assert.Equal(t, []*mail.Address{
+		{Name: "Bob", Address: "bob@example.com"},
+		{Address: "carol@example.com"},
+	}, s.Recipients)
+}
+
+func TestLoadWithInvalidMailAddress(t *testing.T) {
+	// Arrange
+	type S struct {
+		Value mail.Address `env:"TEST_VALUE"`
+	}
+
+	os.Setenv("TEST_VALUE", "not an address")
+	defer os.Unsetenv("TEST_VALUE")
+
+	// Act
+	var s S
+	err := minienv.Load(&s)
+
+	// Assert
+	assert.Error(t, err)
+
+	conversionErr := err.(minienv.LoadError)
+	assert.Equal(t, "Value", conversionErr.Field)
+	assert.ErrorContains(t, conversionErr, "mail: ")
+}
+
+func TestLoadWithTime(t *testing.T) {
+	// Arrange
+	type S struct {
+		Value time.Time `env:"TEST_VALUE"`
+	}
+
+	os.Setenv("TEST_VALUE", "2023-01-02T15:04:05Z")
+	defer os.Unsetenv("TEST_VALUE")
+
+	// Act
+	var s S
+	err := minienv.Load(&s)
+
+	// Assert
+	assert.Nil(t, err)
+	assert.Equal(t, time.Date(2023, 1, 2, 15, 4, 5, 0, time.UTC), s.Value)
+}
+
+func TestLoadWithRelativeTime(t *testing.T) {
+	// Arrange
+	type S struct {
+		ExpiresAt time.Time `env:"EXPIRES_IN,relative"`
+		StartedAt time.Time `env:"STARTED,relative,default=-1h"`
+	}
+
+	os.Setenv("EXPIRES_IN", "24h")
+	defer os.Unsetenv("EXPIRES_IN")
+
+	// Act
+	var s S
+	err := minienv.Load(&s)
+
+	// Assert
+	assert.Nil(t, err)
+	assert.WithinDuration(t, time.Now().Add(24*time.Hour), s.ExpiresAt, time.Second)
+	assert.WithinDuration(t, time.Now().Add(-time.Hour), s.StartedAt, time.Second)
+}
+
+func TestLoadWithInvalidRelativeTime(t *testing.T) {
+	// Arrange
+	type S struct {
+		Value time.Time `env:"TEST_VALUE,relative"`
+	}
+
+	os.Setenv("TEST_VALUE", "tomorrow")
+	defer os.Unsetenv("TEST_VALUE")
+
+	// Act
+	var s S
+	err := minienv.Load(&s)
+
+	// Assert
+	assert.Error(t, err)
+
+	conversionErr := err.(minienv.LoadError)
+	assert.Equal(t, "Value", conversionErr.Field)
+	assert.ErrorContains(t, conversionErr, "invalid relative time")
+}
+
+func TestLoadWithRelativeOnNonTime(t *testing.T) {
+	// Arrange
+	type S struct {
+		Value string `env:"TEST_VALUE,relative"`
+	}
+
+	// Act
+	var s S
+	err := minienv.Load(&s)
+
+	// Assert
+	assert.Error(t, err)
+	assert.ErrorContains(t, err, "relative can only be used on time.Time fields")
+}
+
+func TestLoadWithFileMode(t *testing.T) {
+	// Arrange
+	type S struct {
+		Umask   os.FileMode `env:"UMASK"`
+		DirMode os.FileMode `env:"DIR_MODE"`
+	}
+
+	os.Setenv("UMASK", "0644")
+	defer os.Unsetenv("UMASK")
+
+	os.Setenv("DIR_MODE", "0o755")
+	defer os.Unsetenv("DIR_MODE")
+
+	// Act
+	var s S
+	err := minienv.Load(&s)
+
+	// Assert
+	assert.Nil(t, err)
+	assert.Equal(t, os.FileMode(0644), s.Umask)
+	assert.Equal(t, os.FileMode(0755), s.DirMode)
+}
+
+func TestLoadWithInvalidFileMode(t *testing.T) {
+	// Arrange
+	type S struct {
+		Value os.FileMode `env:"TEST_VALUE"`
+	}
+
+	os.Setenv("TEST_VALUE", "rwxr-xr-x")
+	defer os.Unsetenv("TEST_VALUE")
+
+	// Act
+	var s S
+	err := minienv.Load(&s)
+
+	// Assert
+	assert.Error(t, err)
+
+	conversionErr := err.(minienv.LoadError)
+	assert.Equal(t, "Value", conversionErr.Field)
+	assert.ErrorContains(t, conversionErr, "invalid file mode \"rwxr-xr-x\"")
+}
+
+func TestReloadPrefix(t *testing.T) {
+	// Arrange
+	type S struct {
+		CacheSize int    `env:"CACHE_SIZE"`
+		CacheTTL  string `env:"CACHE_TTL"`
+		Host      string `env:"HOST"`
+	}
+
+	os.Setenv("CACHE_SIZE", "10")
+	defer os.Unsetenv("CACHE_SIZE")
+
+	os.Setenv("CACHE_TTL", "1m")
+	defer os.Unsetenv("CACHE_TTL")
+
+	os.Setenv("HOST", "localhost")
+	defer os.Unsetenv("HOST")
+
+	var s S
+	err := minienv.Load(&s)
+	assert.Nil(t, err)
+
+	os.Setenv("CACHE_SIZE", "20")
+	os.Setenv("HOST", "example.com")
+
+	// Act
+	err = minienv.ReloadPrefix(&s, "CACHE_")
+
+	// Assert
+	assert.Nil(t, err)
+	assert.Equal(t, 20, s.CacheSize)
+	assert.Equal(t, "1m", s.CacheTTL)
+	assert.Equal(t, "localhost", s.Host)
+}
+
+func TestReloadPrefixSkipsMissingFields(t *testing.T) {
+	// Arrange
+	type S struct {
+		CacheSize int    `env:"CACHE_SIZE"`
+		Host      string `env:"HOST"` // required, but not part of the reload
+	}
+
+	os.Setenv("CACHE_SIZE", "10")
+	defer os.Unsetenv("CACHE_SIZE")
+
+	// Act
+	var s S
+	err := minienv.ReloadPrefix(&s, "CACHE_")
+
+	// Assert
+	assert.Nil(t, err)
+	assert.Equal(t, 10, s.CacheSize)
+	assert.Equal(t, "", s.Host)
+}
+
+func TestReloadPrefixSkipsIndexedSlices(t *testing.T) {
+	// Arrange
+	type Server struct {
+		Host string `env:"HOST"`
+	}
+
+	type S struct {
+		CacheSize int      `env:"CACHE_SIZE"`
+		Servers   []Server `env:"SERVERS"`
+	}
+
+	os.Setenv("CACHE_SIZE", "10")
+	defer os.Unsetenv("CACHE_SIZE")
+
+	// Act
+	s := S{Servers: []Server{{Host: "a"}}}
+	err := minienv.ReloadPrefix(&s, "CACHE_")
+
+	// Assert
+	assert.Nil(t, err)
+	assert.Equal(t, 10, s.CacheSize)
+	assert.Equal(t, []Server{{Host: "a"}}, s.Servers)
+}
+
+func TestReloadPrefixOfIndexedElement(t *testing.T) {
+	// Arrange
+	type Server struct {
+		Host string `env:"HOST"`
+	}
+
+	type S struct {
+		CacheSize int      `env:"CACHE_SIZE"`
+		Servers   []Server `env:"SERVERS"`
+	}
+
+	os.Setenv("SERVERS_0_HOST", "a")
+	defer os.Unsetenv("SERVERS_0_HOST")
+
+	os.Setenv("SERVERS_1_HOST", "b")
+	defer os.Unsetenv("SERVERS_1_HOST")
+
+	// Act
+	s := S{CacheSize: 10}
+	err := minienv.ReloadPrefix(&s, "SERVERS_1_")
+
+	// Assert
+	assert.Nil(t, err)
+	assert.Equal(t, 10, s.CacheSize)
+	assert.Equal(t, []Server{{Host: "a"}, {Host: "b"}}, s.Servers)
+}
+
+// A custom type that validates itself
+type LogLevel string
+
+func (l *LogLevel) SetEnv(value string) error {
+	switch strings.ToLower(value) {
+	case "debug", "info", "warn", "error":
+		*l = LogLevel(strings.ToLower(value))
+		return nil
+	}
+
+	return fmt.Errorf("invalid log level: %s", value)
+}
+
+func TestLoadWithSetter(t *testing.T) {
+	// Arrange
+	type S struct {
+		Level  LogLevel   `env:"LEVEL"`
+		Levels []LogLevel `env:"LEVELS"`
+	}
+
+	os.Setenv("LEVEL", "INFO")
+	defer os.Unsetenv("LEVEL")
+
+	os.Setenv("LEVELS", "Debug|warn")
+	defer os.Unsetenv("LEVELS")
+
+	// Act
+	var s S
+	err := minienv.Load(&s)
+
+	// Assert
+	assert.Nil(t, err)
+	assert.Equal(t, LogLevel("info"), s.Level)
+	assert.Equal(t, []LogLevel{"debug", "warn"}, s.Levels)
+}
+
+func TestLoadWithInvalidSetterSliceElement(t *testing.T) {
+	// Arrange
+	type S struct {
+		Levels []LogLevel `env:"LEVELS"`
+	}
+
+	os.Setenv("LEVELS", "debug|verbose")
+	defer os.Unsetenv("LEVELS")
+
+	// Act
+	var s S
+	err := minienv.Load(&s)
+
+	// Assert
+	assert.Error(t, err)
+
+	conversionErr := err.(minienv.LoadError)
+	assert.Equal(t, "Levels", conversionErr.Field)
+	assert.ErrorContains(t, conversionErr, "failed to parse element at index 1: invalid log level: verbose")
+}
+
+func TestLoadWithTextUnmarshalerSliceElement(t *testing.T) {
+	// Arrange
+	type S struct {
+		Levels []slog.Level `env:"LEVELS"`
+	}
+
+	os.Setenv("LEVELS", "debug|error")
+	defer os.Unsetenv("LEVELS")
+
+	// Act
+	var s S
+	err := minienv.Load(&s)
+
+	// Assert
+	assert.Nil(t, err)
+	assert.Equal(t, []slog.Level{slog.LevelDebug, slog.LevelError}, s.Levels)
+}
+
+func TestLoadWithEmptySliceElements(t *testing.T) {
+	tests := map[string]struct {
+		value    string
+		expected []string
+	}{
+		"leading":  {value: "|a|b", expected: []string{"", "a", "b"}},
+		"trailing": {value: "a|b|", expected: []string{"a", "b", ""}},
+		"doubled":  {value: "a||b", expected: []string{"a", "", "b"}},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			// Arrange
+			type S struct {
+				Value []string `env:"TEST_VALUE"`
+			}
+
+			os.Setenv("TEST_VALUE", test.value)
+			defer os.Unsetenv("TEST_VALUE")
+
+			// Act
+			var s S
+			err := minienv.Load(&s)
+
+			// Assert
+			assert.Nil(t, err)
+			assert.Equal(t, test.expected, s.Value)
+		})
+	}
+}
+
+func TestLoadWithSkipEmpty(t *testing.T) {
+	tests := map[string]string{
+		"leading":  "|1|2",
+		"trailing": "1|2|",
+		"doubled":  "1||2",
+	}
+
+	for name, value := range tests {
+		t.Run(name, func(t *testing.T) {
+			// Arrange
+			type S struct {
+				Strings []string `env:"TEST_STRINGS,skipempty"`
+				Ints    []int    `env:"TEST_INTS,skipempty"`
+			}
+
+			os.Setenv("TEST_STRINGS", value)
+			defer os.Unsetenv("TEST_STRINGS")
+
+			os.Setenv("TEST_INTS", value)
+			defer os.Unsetenv("TEST_INTS")
+
+			// Act
+			var s S
+			err := minienv.Load(&s)
+
+			// Assert
+			assert.Nil(t, err)
+			assert.Equal(t, []string{"1", "2"}, s.Strings)
+			assert.Equal(t, []int{1, 2}, s.Ints)
+		})
+	}
+}
+
+func TestLoadWithBracketedSlice(t *testing.T) {
+	tests := map[string]struct {
+		value    string
+		expected []string
+	}{
+		"bracketed":        {value: "[a,b,c]", expected: []string{"a", "b", "c"}},
+		"bracketed spaces": {value: "[ a, b , c ]", expected: []string{"a", "b", "c"}},
+		"bracketed pipe":   {value: "[a|b,c]", expected: []string{"a|b", "c"}},
+		"empty brackets":   {value: "[]", expected: []string{}},
+		"plain":            {value: "a|b,c", expected: []string{"a", "b,c"}},
+		"open bracket":     {value: "[a|b", expected: []string{"[a", "b"}},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			// Arrange
+			type S struct {
+				Value []string `env:"TEST_VALUE"`
+			}
+
+			os.Setenv("TEST_VALUE", test.value)
+			defer os.Unsetenv("TEST_VALUE")
+
+			// Act
+			var s S
+			err := minienv.Load(&s)
+
+			// Assert
+			assert.Nil(t, err)
+			assert.Equal(t, test.expected, s.Value)
+		})
+	}
+}
+
+func TestLoadWithBracketedDefault(t *testing.T) {
+	// Arrange
+	type S struct {
+		Tags  []string `env:"TAGS,default=[a,b,c],optional"`
+		Ports []int    `env:"PORTS,default=[ 80, 443 ]"`
+		Host  string   `env:"HOST,default=[::1]:80"`
+	}
+
+	// Act
+	var s S
+	err := minienv.Load(&s)
+
+	// Assert
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"a", "b", "c"}, s.Tags)
+	assert.Equal(t, []int{80, 443}, s.Ports)
+	assert.Equal(t, "[::1]:80", s.Host)
+}
+
+func TestLoadWithUnclosedBracketedDefault(t *testing.T) {
+	// Arrange
+	type S struct {
+		Tags []string `env:"TAGS,default=[a,b"`
+	}
+
+	// Act
+	var s S
+	err := minienv.Load(&s)
+
+	// Assert
+	assert.Error(t, err)
+
+	tagParseErr := err.(minienv.LoadError)
+	assert.Equal(t, "Tags", tagParseErr.Field)
+	assert.ErrorContains(t, tagParseErr, "default list is missing its closing bracket")
+}
+
+func TestLoadWithDedup(t *testing.T) {
+	// Arrange
+	type S struct {
+		Tags      []string        `env:"TAGS,dedup"`
+		Ports     []int           `env:"PORTS,dedup"`
+		Durations []time.Duration `env:"DURATIONS,dedup"`
+	}
+
+	os.Setenv("TAGS", "b|a|b|c|a")
+	defer os.Unsetenv("TAGS")
+
+	os.Setenv("PORTS", "443|80|0443|80")
+	defer os.Unsetenv("PORTS")
+
+	os.Setenv("DURATIONS", "60s|1m|30s")
+	defer os.Unsetenv("DURATIONS")
+
+	// Act
+	var s S
+	err := minienv.Load(&s)
+
+	// Assert
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"b", "a", "c"}, s.Tags)
+	assert.Equal(t, []int{443, 80}, s.Ports)
+	assert.Equal(t, []time.Duration{time.Minute, 30 * time.Second}, s.Durations)
+}
+
+func TestLoadWithCountPrefix(t *testing.T) {
+	tests := map[string]struct {
+		value    string
+		expected []string
+		err      string
+	}{
+		"matching":    {value: "3|a|b|c", expected: []string{"a", "b", "c"}},
+		"zero":        {value: "0", expected: []string{}},
+		"too few":     {value: "3|a|b", err: "count prefix announces 3 elements, but 2 were given"},
+		"too many":    {value: "1|a|b", err: "count prefix announces 1 elements, but 2 were given"},
+		"non-numeric": {value: "a|b|c", err: "invalid count prefix \"a\""},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			// Arrange
+			type S struct {
+				Value []string `env:"TEST_VALUE,countprefix"`
+			}
+
+			os.Setenv("TEST_VALUE", test.value)
+			defer os.Unsetenv("TEST_VALUE")
+
+			// Act
+			var s S
+			err := minienv.Load(&s)
+
+			// Assert
+			if test.err != "" {
+				assert.Error(t, err)
+				assert.ErrorContains(t, err, test.err)
+				return
+			}
+
+			assert.Nil(t, err)
+			assert.Equal(t, test.expected, s.Value)
+		})
+	}
+}
+
+func TestLoadWithUint(t *testing.T) {
+	// Arrange
+	type S struct {
+		Port    uint16            `env:"PORT"`
+		Size    uint64            `env:"SIZE"`
+		Small   uint8             `env:"SMALL"`
+		Plain   uint              `env:"PLAIN"`
+		Weights []uint32          `env:"WEIGHTS"`
+		Limits  map[string]uint16 `env:"LIMITS"`
+	}
+
+	os.Setenv("PORT", "65535")
+	defer os.Unsetenv("PORT")
+
+	os.Setenv("SIZE", "18446744073709551615")
+	defer os.Unsetenv("SIZE")
+
+	os.Setenv("SMALL", "255")
+	defer os.Unsetenv("SMALL")
+
+	os.Setenv("PLAIN", "42")
+	defer os.Unsetenv("PLAIN")
+
+	os.Setenv("WEIGHTS", "1|2|3")
+	defer os.Unsetenv("WEIGHTS")
+
+	os.Setenv("LIMITS", "free:10|pro:100")
+	defer os.Unsetenv("LIMITS")
+
+	// Act
+	var s S
+	err := minienv.Load(&s)
+
+	// Assert
+	assert.Nil(t, err)
+	assert.Equal(t, uint16(65535), s.Port)
+	assert.Equal(t, uint64(18446744073709551615), s.Size)
+	assert.Equal(t, uint8(255), s.Small)
+	assert.Equal(t, uint(42), s.Plain)
+	assert.Equal(t, []uint32{1, 2, 3}, s.Weights)
+	assert.Equal(t, map[string]uint16{"free": 10, "pro": 100}, s.Limits)
+}
+
+func TestLoadWithInvalidUint(t *testing.T) {
+	tests := map[string]string{
+		"overflow": "65536",
+		"negative": "-1",
+		"text":     "port",
+	}
+
+	for name, value := range tests {
+		t.Run(name, func(t *testing.T) {
+			// Arrange
+			type S struct {
+				Port uint16 `env:"PORT"`
+			}
+
+			os.Setenv("PORT", value)
+			defer os.Unsetenv("PORT")
+
+			// Act
+			var s S
+			err := minienv.Load(&s)
+
+			// Assert
+			assert.Error(t, err)
+
+			loadErr := err.(minienv.LoadError)
+			assert.Equal(t, "Port", loadErr.Field)
+
+			var numErr *strconv.NumError
+			assert.ErrorAs(t, err, &numErr)
+		})
+	}
+}
+
+func TestLoadWithQuotedSliceElements(t *testing.T) {
+	tests := map[string]struct {
+		value    string
+		expected []string
+	}{
+		"quoted empty":     {value: `a|""|b`, expected: []string{"a", "", "b"}},
+		"bare empty":       {value: "a||b", expected: []string{"a", "b"}},
+		"mixed":            {value: `""||a|`, expected: []string{"", "a"}},
+		"quoted delimiter": {value: `"a|b"|c`, expected: []string{"a|b", "c"}},
+		"inner quote":      {value: `a"b|c`, expected: []string{`a"b`, "c"}},
+		"inner quotes":     {value: `a"b|c"d|e`, expected: []string{`a"b`, `c"d`, "e"}},
+		"unclosed quote":   {value: `"a|b`, expected: []string{`"a`, "b"}},
+		"quote in quoted":  {value: `"a"b"|c`, expected: []string{`a"b`, "c"}},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			// Arrange
+			type S struct {
+				Value []string `env:"TEST_VALUE,skipempty"`
+			}
+
+			os.Setenv("TEST_VALUE", test.value)
+			defer os.Unsetenv("TEST_VALUE")
+
+			// Act
+			var s S
+			err := minienv.Load(&s)
+
+			// Assert
+			assert.Nil(t, err)
+			assert.Equal(t, test.expected, s.Value)
+		})
+	}
+}
+
+func TestLoadWithTrailingDelimiterInIntSlice(t *testing.T) {
+	// Arrange
+	type S struct {
+		Value []int `env:"TEST_VALUE"`
+	}
+
+	os.Setenv("TEST_VALUE", "1|2|")
+	defer os.Unsetenv("TEST_VALUE")
+
+	// Act
+	var s S
+	err := minienv.Load(&s)
+
+	// Assert
+	assert.Error(t, err)
+	assert.ErrorContains(t, err, "failed to parse element at index 2")
+}
+
+func TestLoadWithDefaultFrom(t *testing.T) {
+	// Arrange
+	type S struct {
+		DisplayName string `env:"DISPLAY_NAME,defaultfrom=Username"`
+		Username    string `env:"USERNAME"`
+		Alias       string `env:"ALIAS,defaultfrom=Username"`
+	}
+
+	os.Setenv("USERNAME", "alice")
+	defer os.Unsetenv("USERNAME")
+
+	os.Setenv("ALIAS", "ally")
+	defer os.Unsetenv("ALIAS")
+
+	// Act
+	var s S
+	err := minienv.Load(&s)
+
+	// Assert
+	assert.Nil(t, err)
+	assert.Equal(t, "alice", s.DisplayName)
+	assert.Equal(t, "ally", s.Alias)
+}
+
+func TestLoadWithDefaultFromUnknownField(t *testing.T) {
+	// Arrange
+	type S struct {
+		DisplayName string `env:"DISPLAY_NAME,defaultfrom=Missing"`
+	}
+
+	// Act
+	var s S
+	err := minienv.Load(&s)
+
+	// Assert
+	assert.Error(t, err)
+
+	defaultErr := err.(minienv.LoadError)
+	assert.Equal(t, "DisplayName", defaultErr.Field)
+	assert.ErrorContains(t, defaultErr, "unknown field in defaultfrom: Missing")
+}
+
+func TestLoadWithDefaultFromUnexportedField(t *testing.T) {
+	// Arrange
+	type S struct {
+		username    string
+		DisplayName string `env:"DISPLAY_NAME,defaultfrom=username"`
+	}
+
+	// Act
+	s := S{username: "alice"}
+	err := minienv.Load(&s)
+
+	// Assert
+	assert.Error(t, err)
+
+	defaultErr := err.(minienv.LoadError)
+	assert.Equal(t, "DisplayName", defaultErr.Field)
+	assert.ErrorContains(t, defaultErr, "unexported field in defaultfrom: username")
+}
+
+func TestLoadWithDefaultFromMismatchedType(t *testing.T) {
+	// Arrange
+	type S struct {
+		Port    int    `env:"PORT"`
+		Display string `env:"DISPLAY,defaultfrom=Port"`
+	}
+
+	os.Setenv("PORT", "8080")
+	defer os.Unsetenv("PORT")
+
+	// Act
+	var s S
+	err := minienv.Load(&s)
+
+	// Assert
+	assert.Error(t, err)
+	assert.ErrorContains(t, err, "field Port of type int can't be used as default for type string")
+}
+
+func TestLoadWithURL(t *testing.T) {
+	// Arrange
+	type S struct {
+		Value   url.URL  `env:"TEST_VALUE"`
+		Pointer *url.URL `env:"TEST_POINTER"`
+	}
+
+	os.Setenv("TEST_VALUE", "https://api.example.com/v1")
+	defer os.Unsetenv("TEST_VALUE")
+
+	os.Setenv("TEST_POINTER", "postgres://localhost:5432/db")
+	defer os.Unsetenv("TEST_POINTER")
+
+	// Act
+	var s S
+	err := minienv.Load(&s)
+
+	// Assert
+	assert.Nil(t, err)
+	assert.Equal(t, "https://api.example.com/v1", s.Value.String())
+	assert.Equal(t, "postgres://localhost:5432/db", s.Pointer.String())
+}
+
+func TestLoadWithURLSchemes(t *testing.T) {
+	// Arrange
+	type S struct {
+		Value *url.URL `env:"TEST_VALUE,schemes=https|wss"`
+	}
+
+	os.Setenv("TEST_VALUE", "wss://example.com")
+	defer os.Unsetenv("TEST_VALUE")
+
+	// Act
+	var s S
+	err := minienv.Load(&s)
+
+	// Assert
+	assert.Nil(t, err)
+	assert.Equal(t, "wss", s.Value.Scheme)
+}
+
+func TestLoadWithDisallowedURLScheme(t *testing.T) {
+	// Arrange
+	type S struct {
+		Value url.URL `env:"TEST_VALUE,schemes=https"`
+	}
+
+	os.Setenv("TEST_VALUE", "http://example.com")
+	defer os.Unsetenv("TEST_VALUE")
+
+	// Act
+	var s S
+	err := minienv.Load(&s)
+
+	// Assert
+	assert.Error(t, err)
+
+	schemeErr := err.(minienv.LoadError)
+	assert.Equal(t, "Value", schemeErr.Field)
+	assert.ErrorContains(t, schemeErr, "url scheme \"http\" is not allowed, expected one of: https")
+}
+
+func TestLoadWithInvalidURL(t *testing.T) {
+	// Arrange
+	type S struct {
+		Value url.URL `env:"TEST_VALUE"`
+	}
+
+	os.Setenv("TEST_VALUE", "://missing-scheme")
+	defer os.Unsetenv("TEST_VALUE")
+
+	// Act
+	var s S
+	err := minienv.Load(&s)
+
+	// Assert
+	assert.Error(t, err)
+
+	conversionErr := err.(minienv.LoadError)
+	assert.Equal(t, "Value", conversionErr.Field)
+	assert.ErrorContains(t, conversionErr, "missing protocol scheme")
+}
+
+func TestLoadWithURLDefault(t *testing.T) {
+	// Arrange
+	type S struct {
+		Value   url.URL  `env:"API_BASE,default=http://localhost:8080/v1"`
+		Pointer *url.URL `env:"API_POINTER,default=https://api.example.com"`
+	}
+
+	// Act
+	var s S
+	err := minienv.Load(&s)
+
+	// Assert
+	assert.Nil(t, err)
+	assert.Equal(t, "http", s.Value.Scheme)
+	assert.Equal(t, "localhost:8080", s.Value.Host)
+	assert.Equal(t, "/v1", s.Value.Path)
+	assert.Equal(t, "https://api.example.com", s.Pointer.String())
+}
+
+func TestLoadWithContiguousMap(t *testing.T) {
+	// Arrange
+	type S struct {
+		Stages map[int]string `env:"STAGES,contiguous"`
+	}
+
+	os.Setenv("STAGES", "3:deploy|1:build|2:test")
+	defer os.Unsetenv("STAGES")
+
+	// Act
+	var s S
+	err := minienv.Load(&s)
+
+	// Assert
+	assert.Nil(t, err)
+	assert.Equal(t, map[int]string{1: "build", 2: "test", 3: "deploy"}, s.Stages)
+}
+
+func TestLoadWithGappedContiguousMap(t *testing.T) {
+	// Arrange
+	type S struct {
+		Stages map[int]string `env:"STAGES,contiguous"`
+	}
+
+	os.Setenv("STAGES", "1:build|2:test|4:deploy")
+	defer os.Unsetenv("STAGES")
+
+	// Act
+	var s S
+	err := minienv.Load(&s)
+
+	// Assert
+	assert.Error(t, err)
+
+	gapErr := err.(minienv.LoadError)
+	assert.Equal(t, "Stages", gapErr.Field)
+	assert.ErrorContains(t, gapErr, "map keys are not contiguous, missing key 3")
+}
+
+func TestLoadWithContiguousOnStringKeys(t *testing.T) {
+	// Arrange
+	type S struct {
+		Stages map[string]string `env:"STAGES,contiguous"`
+	}
+
+	// Act
+	var s S
+	err := minienv.Load(&s)
+
+	// Assert
+	assert.Error(t, err)
+	assert.ErrorContains(t, err, "contiguous can only be used on maps with int keys")
+}
+
+func TestLoadWithDerivedKey(t *testing.T) {
+	// Arrange
+	type S struct {
+		DisplayName string `env:",optional"`
+		HTTPTimeout int    `env:""`
+		Port        int    `env:",default=8080"`
+	}
+
+	os.Setenv("DISPLAY_NAME", "alice")
+	defer os.Unsetenv("DISPLAY_NAME")
+
+	os.Setenv("HTTP_TIMEOUT", "30")
+	defer os.Unsetenv("HTTP_TIMEOUT")
+
+	// Act
+	var s S
+	err := minienv.Load(&s)
+
+	// Assert
+	assert.Nil(t, err)
+	assert.Equal(t, "alice", s.DisplayName)
+	assert.Equal(t, 30, s.HTTPTimeout)
+	assert.Equal(t, 8080, s.Port)
+}
+
+func TestLoadWithDerivedKeyAndPrefix(t *testing.T) {
+	// Arrange
+	type S struct {
+		MaxConns int `env:""`
+	}
+
+	os.Setenv("DB_MAX_CONNS", "10")
+	defer os.Unsetenv("DB_MAX_CONNS")
+
+	// Act
+	var s S
+	err := minienv.Load(&s, minienv.WithPrefix("DB_"))
+
+	// Assert
+	assert.Nil(t, err)
+	assert.Equal(t, 10, s.MaxConns)
+}
+
+func TestLoadWithJSON(t *testing.T) {
+	// Arrange
+	type S struct {
+		Rules  []map[string]string `env:"RULES,json"`
+		Limits map[string]int      `env:"LIMITS,json"`
+	}
+
+	os.Setenv("RULES", `[{"path": "/api", "action": "allow"}, {"path": "/admin", "action": "deny"}]`)
+	defer os.Unsetenv("RULES")
+
+	os.Setenv("LIMITS", `{"free": 10, "pro": 100}`)
+	defer os.Unsetenv("LIMITS")
+
+	// Act
+	var s S
+	err := minienv.Load(&s)
+
+	// Assert
+	assert.Nil(t, err)
+	assert.Equal(t, []map[string]string{
+		{"path": "/api", "action": "allow"},
+		{"path": "/admin", "action": "deny"},
+	}, s.Rules)
+	assert.Equal(t, map[string]int{"free": 10, "pro": 100}, s.Limits)
+}
+
+func TestLoadWithInvalidJSON(t *testing.T) {
+	// Arrange
+	type S struct {
+		Rules []map[string]string `env:"RULES,json"`
+	}
+
+	os.Setenv("RULES", `[{"path": "/api"`)
+	defer os.Unsetenv("RULES")
+
+	// Act
+	var s S
+	err := minienv.Load(&s)
+
+	// Assert
+	assert.Error(t, err)
+
+	jsonErr := err.(minienv.LoadError)
+	assert.Equal(t, "Rules", jsonErr.Field)
+	assert.ErrorContains(t, jsonErr, "invalid JSON")
+}
+
+func TestLoadWithSemverFormat(t *testing.T) {
+	for _, version := range []string{"1.2.3", "v0.10.0", "2.0.0-rc.1", "1.0.0+build.5"} {
+		t.Run(version, func(t *testing.T) {
+			// Arrange
+			type S struct {
+				Value string `env:"MIN_VERSION,format=semver"`
+			}
+
+			os.Setenv("MIN_VERSION", version)
+			defer os.Unsetenv("MIN_VERSION")
+
+			// Act
+			var s S
+			err := minienv.Load(&s)
+
+			// Assert
+			assert.Nil(t, err)
+			assert.Equal(t, version, s.Value)
+		})
+	}
+}
+
+func TestLoadWithInvalidSemver(t *testing.T) {
+	for _, version := range []string{"1.2", "1.2.3.4", "01.2.3", "latest"} {
+		t.Run(version, func(t *testing.T) {
+			// Arrange
+			type S struct {
+				Value string `env:"MIN_VERSION,format=semver"`
+			}
+
+			os.Setenv("MIN_VERSION", version)
+			defer os.Unsetenv("MIN_VERSION")
+
+			// Act
+			var s S
+			err := minienv.Load(&s)
+
+			// Assert
+			assert.Error(t, err)
+
+			formatErr := err.(minienv.LoadError)
+			assert.Equal(t, "Value", formatErr.Field)
+			assert.ErrorContains(t, formatErr, "is not a valid semantic version")
+		})
+	}
+}
+
+func TestLoadWithStringFormats(t *testing.T) {
+	tests := map[string]struct {
+		valid   []string
+		invalid []string
+		err     string
+	}{
+		"email": {
+			valid:   []string{"user@example.com", "first.last+tag@sub.example.org"},
+			invalid: []string{"user", "@example.com", "User <user@example.com>"},
+			err:     "is not a valid email address",
+		},
+		"url": {
+			valid:   []string{"https://example.com", "postgres://user:pw@db:5432/app"},
+			invalid: []string{"example.com", "/relative/path", "https://"},
+			err:     "is not a valid url",
+		},
+		"uuid": {
+			valid:   []string{"123e4567-e89b-12d3-a456-426614174000", "123E4567-E89B-12D3-A456-426614174000"},
+			invalid: []string{"123e4567e89b12d3a456426614174000", "123e4567-e89b-12d3-a456-42661417400g"},
+			err:     "is not a valid uuid",
+		},
+		"hostname": {
+			valid:   []string{"localhost", "api.example.com", "db-1"},
+			invalid: []string{"-db", "api..example.com", "under_score.com"},
+			err:     "is not a valid hostname",
+		},
+		"ipv4": {
+			valid:   []string{"127.0.0.1", "192.168.0.255"},
+			invalid: []string{"256.0.0.1", "::1", "localhost"},
+			err:     "is not a valid ipv4 address",
+		},
+		"ipv6": {
+			valid:   []string{"::1", "2001:db8::8a2e:370:7334"},
+			invalid: []string{"127.0.0.1", "2001:db8::g", "localhost"},
+			err:     "is not a valid ipv6 address",
+		},
+	}
+
+	for format, test := range tests {
+		field := reflect.StructField{
+			Name: "Value",
+			Type: reflect.TypeOf(""),
+			Tag:  reflect.StructTag(fmt.Sprintf(`env:"TEST_VALUE,format=%s"`, format)),
+		}
+
+		for _, value := range test.valid {
+			t.Run(format+"/"+value, func(t *testing.T) {
+				// Arrange
+				os.Setenv("TEST_VALUE", value)
+				defer os.Unsetenv("TEST_VALUE")
+
+				obj := reflect.New(reflect.StructOf([]reflect.StructField{field}))
+
+				// Act
+				err := minienv.Load(obj.Interface())
+
+				// Assert
+				assert.Nil(t, err)
+				assert.Equal(t, value, obj.Elem().Field(0).String())
+			})
+		}
+
+		for _, value := range test.invalid {
+			t.Run(format+"/"+value, func(t *testing.T) {
+				// Arrange
+				os.Setenv("TEST_VALUE", value)
+				defer os.Unsetenv("TEST_VALUE")
+
+				obj := reflect.New(reflect.StructOf([]reflect.StructField{field}))
+
+				// Act
+				err := minienv.Load(obj.Interface())
+
+				// Assert
+				assert.Error(t, err)
+				assert.ErrorContains(t, err, fmt.Sprintf("value \"%s\" %s", value, test.err))
+			})
+		}
+	}
+}
+
+func TestLoadWithStringFormatOnInvalidType(t *testing.T) {
+	// Arrange
+	type S struct {
+		Value int `env:"TEST_VALUE,format=uuid"`
+	}
+
+	// Act
+	var s S
+	err := minienv.Load(&s)
+
+	// Assert
+	assert.Error(t, err)
+	assert.ErrorContains(t, err, "format uuid can only be used on string fields")
+}
+
+func TestLoadWithOptionalSemver(t *testing.T) {
+	// Arrange
+	type S struct {
+		Value string `env:"MIN_VERSION,format=semver,optional"`
+	}
+
+	// Act
+	var s S
+	err := minienv.Load(&s)
+
+	// Assert
+	assert.Nil(t, err)
+	assert.Equal(t, "", s.Value)
+}
+
+type PortRange struct {
+	Min int
+	Max int
+}
+
+func TestLoadWithRangeFormat(t *testing.T) {
+	// Arrange
+	type S struct {
+		Ports  PortRange `env:"PORT_RANGE,format=range"`
+		Single PortRange `env:"SINGLE,format=range,default=80-80"`
+	}
+
+	os.Setenv("PORT_RANGE", "8000-8100")
+	defer os.Unsetenv("PORT_RANGE")
+
+	// Act
+	var s S
+	err := minienv.Load(&s)
+
+	// Assert
+	assert.Nil(t, err)
+	assert.Equal(t, PortRange{Min: 8000, Max: 8100}, s.Ports)
+	assert.Equal(t, PortRange{Min: 80, Max: 80}, s.Single)
+}
+
+func TestLoadWithInvalidRange(t *testing.T) {
+	tests := map[string]struct {
+		value string
+		err   string
+	}{
+		"inverted":        {value: "8100-8000", err: "min is greater than max"},
+		"missing max":     {value: "8000", err: "expected format <min>-<max>"},
+		"not a number":    {value: "a-b", err: "invalid range \"a-b\""},
+		"negative bounds": {value: "-10-10", err: "invalid range \"-10-10\""},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			// Arrange
+			type S struct {
+				Ports PortRange `env:"PORT_RANGE,format=range"`
+			}
+
+			os.Setenv("PORT_RANGE", test.value)
+			defer os.Unsetenv("PORT_RANGE")
+
+			// Act
+			var s S
+			err := minienv.Load(&s)
+
+			// Assert
+			assert.Error(t, err)
+
+			rangeErr := err.(minienv.LoadError)
+			assert.Equal(t, "Ports", rangeErr.Field)
+			assert.ErrorContains(t, rangeErr, test.err)
+		})
+	}
+}
+
+func TestLoadWithRangeFormatOnInvalidType(t *testing.T) {
+	// Arrange
+	type S struct {
+		Ports string `env:"PORT_RANGE,format=range"`
+	}
+
+	// Act
+	var s S
+	err := minienv.Load(&s)
+
+	// Assert
+	assert.Error(t, err)
+	assert.ErrorContains(t, err, "format range can only be used on structs with int Min and Max fields")
+}
+
+func TestLoadWithDurationUnit(t *testing.T) {
+	// Arrange
+	type S struct {
+		FromEnv     time.Duration `env:"FROM_ENV,unit=seconds"`
+		FromDefault time.Duration `env:"FROM_DEFAULT,unit=seconds,default=30"`
+		WithSuffix  time.Duration `env:"WITH_SUFFIX,unit=ms"`
+		Fractional  time.Duration `env:"FRACTIONAL,unit=minutes"`
+	}
+
+	os.Setenv("FROM_ENV", "5")
+	defer os.Unsetenv("FROM_ENV")
+
+	os.Setenv("WITH_SUFFIX", "2s")
+	defer os.Unsetenv("WITH_SUFFIX")
+
+	os.Setenv("FRACTIONAL", "1.5")
+	defer os.Unsetenv("FRACTIONAL")
+
+	// Act
+	var s S
+	err := minienv.Load(&s)
+
+	// Assert
+	assert.Nil(t, err)
+	assert.Equal(t, 5*time.Second, s.FromEnv)
+	assert.Equal(t, 30*time.Second, s.FromDefault)
+	assert.Equal(t, 2*time.Second, s.WithSuffix)
+	assert.Equal(t, 90*time.Second, s.Fractional)
+}
+
+func TestLoadWithUnknownDurationUnit(t *testing.T) {
+	// Arrange
+	type S struct {
+		Value time.Duration `env:"TEST_VALUE,unit=days"`
+	}
+
+	// Act
+	var s S
+	err := minienv.Load(&s)
+
+	// Assert
+	assert.Error(t, err)
+	assert.ErrorContains(t, err, "unknown unit: days")
+}
+
+func TestLoadWithValueMapping(t *testing.T) {
+	// Arrange
+	type S struct {
+		Mapped   string `env:"MAPPED,map=prod:production|stg:staging"`
+		Unmapped string `env:"UNMAPPED,map=prod:production|stg:staging"`
+		Default  string `env:"DEFAULT,map=prod:production,default=prod"`
+	}
+
+	os.Setenv("MAPPED", "stg")
+	defer os.Unsetenv("MAPPED")
+
+	os.Setenv("UNMAPPED", "development")
+	defer os.Unsetenv("UNMAPPED")
+
+	// Act
+	var s S
+	err := minienv.Load(&s)
+
+	// Assert
+	assert.Nil(t, err)
+	assert.Equal(t, "staging", s.Mapped)
+	assert.Equal(t, "development", s.Unmapped)
+	assert.Equal(t, "production", s.Default)
+}
+
+func TestLoadWithInvalidValueMapping(t *testing.T) {
+	// Arrange
+	type S struct {
+		Value string `env:"TEST_VALUE,map=prod"`
+	}
+
+	// Act
+	var s S
+	err := minienv.Load(&s)
+
+	// Assert
+	assert.Error(t, err)
+	assert.ErrorContains(t, err, "invalid map entry: prod")
+}
+
+func TestLoadWithWhitespaceKey(t *testing.T) {
+	// Arrange
+	type S struct {
+		Value string `env:"  ,optional"`
+	}
+
+	// Act
+	var s S
+	err := minienv.Load(&s)
+
+	// Assert
+	assert.Error(t, err)
+
+	tagParseErr := err.(minienv.LoadError)
+	assert.Equal(t, "Value", tagParseErr.Field)
+	assert.ErrorContains(t, tagParseErr, "key must not only consist of whitespace")
+}
+
+func TestLoadWithPaddedKey(t *testing.T) {
+	// Arrange
+	type S struct {
+		Value string `env:" TEST_VALUE "`
+	}
+
+	os.Setenv("TEST_VALUE", "test-value")
+	defer os.Unsetenv("TEST_VALUE")
+
+	// Act
+	var s S
+	err := minienv.Load(&s)
+
+	// Assert
+	assert.Nil(t, err)
+	assert.Equal(t, "test-value", s.Value)
+}
+
+func TestLoadWithExpr(t *testing.T) {
+	// Arrange
+	type S struct {
+		Workers  int `env:"WORKERS,expr=2*NUMCPU"`
+		Buffer   int `env:"BUFFER,expr=NUMCPU * 4 + 1"`
+		Literal  int `env:"LITERAL,expr=16"`
+		Subtract int `env:"SUBTRACT,expr=10 - 2 * 3"`
+		FromEnv  int `env:"FROM_ENV,expr=2*NUMCPU"`
+	}
+
+	os.Setenv("FROM_ENV", "3")
+	defer os.Unsetenv("FROM_ENV")
+
+	// Act
+	var s S
+	err := minienv.Load(&s)
+
+	// Assert
+	assert.Nil(t, err)
+	assert.Equal(t, 2*runtime.NumCPU(), s.Workers)
+	assert.Equal(t, runtime.NumCPU()*4+1, s.Buffer)
+	assert.Equal(t, 16, s.Literal)
+	assert.Equal(t, 4, s.Subtract)
+	assert.Equal(t, 3, s.FromEnv)
+}
+
+func TestLoadWithInvalidExpr(t *testing.T) {
+	tests := map[string]string{
+		"unknown variable": "2*NUMGPU",
+		"missing operand":  "2*",
+		"invalid char":     "2/NUMCPU",
+	}
+
+	for name, expr := range tests {
+		t.Run(name, func(t *testing.T) {
+			// Arrange
+			field := reflect.StructField{
+				Name: "Value",
+				Type: reflect.TypeOf(0),
+				Tag:  reflect.StructTag(fmt.Sprintf(`env:"TEST_VALUE,expr=%s"`, expr)),
+			}
+
+			obj := reflect.New(reflect.StructOf([]reflect.StructField{field}))
+
+			// Act
+			err := minienv.Load(obj.Interface())
+
+			// Assert
+			assert.Error(t, err)
+			assert.ErrorContains(t, err, fmt.Sprintf("invalid expression \"%s\"", expr))
+		})
+	}
+}
+
+func TestLoadWithExprAndDefault(t *testing.T) {
+	// Arrange
+	type S struct {
+		Value int `env:"TEST_VALUE,expr=NUMCPU,default=2"`
+	}
+
+	// Act
+	var s S
+	err := minienv.Load(&s)
+
+	// Assert
+	assert.Error(t, err)
+	assert.ErrorContains(t, err, "expr and default can't be used together")
+}
+
+func TestLoadWithOnErrorDefault(t *testing.T) {
+	// Arrange
+	type S struct {
+		Workers int           `env:"WORKERS,onerror=default,default=4"`
+		Timeout time.Duration `env:"TIMEOUT,onerror=default,default=5s"`
+	}
+
+	os.Setenv("WORKERS", "many")
+	defer os.Unsetenv("WORKERS")
+
+	os.Setenv("TIMEOUT", "soon")
+	defer os.Unsetenv("TIMEOUT")
+
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&buf, nil))
+
+	// Act
+	var s S
+	entries, err := minienv.LoadWithAudit(&s, minienv.WithLogger(logger))
+
+	// Assert
+	assert.Nil(t, err)
+	assert.Equal(t, 4, s.Workers)
+	assert.Equal(t, 5*time.Second, s.Timeout)
+	assert.Equal(t, minienv.SourceDefault, entries[0].Source)
+	assert.Contains(t, buf.String(), "level=WARN")
+	assert.Contains(t, buf.String(), "key=WORKERS")
+	assert.Contains(t, buf.String(), "key=TIMEOUT")
+}
+
+func TestLoadWithOnErrorDefaultAndNoDefault(t *testing.T) {
+	// Arrange
+	type S struct {
+		Workers int `env:"WORKERS,onerror=default"`
+	}
+
+	os.Setenv("WORKERS", "many")
+	defer os.Unsetenv("WORKERS")
+
+	// Act
+	var s S
+	err := minienv.Load(&s)
+
+	// Assert
+	assert.Error(t, err)
+
+	loadErr := err.(minienv.LoadError)
+	assert.Equal(t, "Workers", loadErr.Field)
+}
+
+func TestLoadWithOnErrorDefaultAndInvalidDefault(t *testing.T) {
+	// Arrange
+	type S struct {
+		Workers int `env:"WORKERS,onerror=default,default=four"`
+	}
+
+	// Act
+	var s S
+	err := minienv.Load(&s)
+
+	// Assert
+	assert.Error(t, err)
+}
+
+func TestLoadWithInvalidOnError(t *testing.T) {
+	// Arrange
+	type S struct {
+		Workers int `env:"WORKERS,onerror=ignore,default=4"`
+	}
+
+	// Act
+	var s S
+	err := minienv.Load(&s)
+
+	// Assert
+	assert.Error(t, err)
+	assert.ErrorContains(t, err, "invalid onerror tag")
+}
+
+func TestLoadWithStdin(t *testing.T) {
+	// Arrange
+	type S struct {
+		Secret string `env:"SECRET,stdin"`
+		Plain  string `env:"PLAIN,stdin"`
+		Dash   string `env:"DASH"`
+	}
+
+	os.Setenv("SECRET", "-")
+	defer os.Unsetenv("SECRET")
+
+	os.Setenv("PLAIN", "value")
+	defer os.Unsetenv("PLAIN")
+
+	os.Setenv("DASH", "-")
+	defer os.Unsetenv("DASH")
+
+	// Act
+	var s S
+	err := minienv.Load(&s, minienv.WithStdin(strings.NewReader("piped secret\n"), 0))
+
+	// Assert
+	assert.Nil(t, err)
+	assert.Equal(t, "piped secret", s.Secret)
+	assert.Equal(t, "value", s.Plain)
+	assert.Equal(t, "-", s.Dash)
+}
+
+func TestLoadWithStdinExceedingLimit(t *testing.T) {
+	// Arrange
+	type S struct {
+		Secret string `env:"SECRET,stdin"`
+	}
+
+	os.Setenv("SECRET", "-")
+	defer os.Unsetenv("SECRET")
+
+	// Act
+	var s S
+	err := minienv.Load(&s, minienv.WithStdin(strings.NewReader("0123456789"), 4))
+
+	// Assert
+	assert.Error(t, err)
+	assert.ErrorContains(t, err, "stdin exceeds the limit of 4 bytes")
+}
+
+func TestLoadWithJoin(t *testing.T) {
+	// Arrange
+	type S struct {
+		Host string `env:"HOST"`
+		Port int    `env:"PORT,default=5432"`
+		Addr string `env:"ADDR,join=HOST|PORT,joinsep=:"`
+		Pair string `env:"PAIR,join=PORT|HOST"`
+	}
+
+	os.Setenv("APP_HOST", "localhost")
+	defer os.Unsetenv("APP_HOST")
+
+	// Act
+	var s S
+	err := minienv.Load(&s, minienv.WithPrefix("APP_"))
+
+	// Assert
+	assert.Nil(t, err)
+	assert.Equal(t, "localhost:5432", s.Addr)
+	assert.Equal(t, "5432localhost", s.Pair)
+}
+
+func TestLoadWithJoinAndMissingComponent(t *testing.T) {
+	// Arrange
+	type S struct {
+		Host string `env:"HOST,optional"`
+		Port int    `env:"PORT,default=5432"`
+		Addr string `env:"ADDR,join=HOST|PORT,joinsep=:"`
+	}
+
+	// Act
+	var s S
+	err := minienv.Load(&s)
+
+	// Assert
+	assert.Error(t, err)
+
+	joinErr := err.(minienv.LoadError)
+	assert.Equal(t, "Addr", joinErr.Field)
+	assert.ErrorContains(t, joinErr, "join component \"HOST\" has no value")
+}
+
+func TestLoadWithJoinAndMissingRequiredComponent(t *testing.T) {
+	// Arrange
+	type S struct {
+		Host string `env:"HOST"`
+		Addr string `env:"ADDR,join=HOST|PORT,joinsep=:"`
+	}
+
+	// Act
+	var s S
+	err := minienv.Load(&s)
+
+	// Assert
+	assert.Error(t, err)
+
+	joinErr := err.(minienv.LoadError)
+	assert.Equal(t, "Host", joinErr.Field)
+}
+
+func TestLoadWithWeekdayAndMonth(t *testing.T) {
+	// Arrange
+	type S struct {
+		Day       time.Weekday   `env:"DAY"`
+		ShortDay  time.Weekday   `env:"SHORT_DAY"`
+		DayNumber time.Weekday   `env:"DAY_NUMBER"`
+		Days      []time.Weekday `env:"DAYS"`
+		Month     time.Month     `env:"MONTH"`
+		Number    time.Month     `env:"MONTH_NUMBER"`
+	}
+
+	os.Setenv("DAY", "sunday")
+	defer os.Unsetenv("DAY")
+
+	os.Setenv("SHORT_DAY", "WED")
+	defer os.Unsetenv("SHORT_DAY")
+
+	os.Setenv("DAY_NUMBER", "6")
+	defer os.Unsetenv("DAY_NUMBER")
+
+	os.Setenv("DAYS", "Saturday|sun|1")
+	defer os.Unsetenv("DAYS")
+
+	os.Setenv("MONTH", "March")
+	defer os.Unsetenv("MONTH")
+
+	os.Setenv("MONTH_NUMBER", "12")
+	defer os.Unsetenv("MONTH_NUMBER")
+
+	// Act
+	var s S
+	err := minienv.Load(&s)
+
+	// Assert
+	assert.Nil(t, err)
+	assert.Equal(t, time.Sunday, s.Day)
+	assert.Equal(t, time.Wednesday, s.ShortDay)
+	assert.Equal(t, time.Saturday, s.DayNumber)
+	assert.Equal(t, []time.Weekday{time.Saturday, time.Sunday, time.Monday}, s.Days)
+	assert.Equal(t, time.March, s.Month)
+	assert.Equal(t, time.December, s.Number)
+}
+
+func TestLoadWithInvalidWeekdayAndMonth(t *testing.T) {
+	tests := map[string]struct {
+		typ   reflect.Type
+		value string
+		err   string
+	}{
+		"unknown weekday": {typ: reflect.TypeOf(time.Weekday(0)), value: "Funday", err: "invalid weekday \"Funday\": unknown name"},
+		"weekday number":  {typ: reflect.TypeOf(time.Weekday(0)), value: "7", err: "invalid weekday \"7\": number must be between 0 and 6"},
+		"unknown month":   {typ: reflect.TypeOf(time.Month(0)), value: "Smarch", err: "invalid month \"Smarch\": unknown name"},
+		"month number":    {typ: reflect.TypeOf(time.Month(0)), value: "0", err: "invalid month \"0\": number must be between 1 and 12"},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			// Arrange
+			field := reflect.StructField{
+				Name: "Value",
+				Type: test.typ,
+				Tag:  `env:"TEST_VALUE"`,
+			}
+
+			os.Setenv("TEST_VALUE", test.value)
+			defer os.Unsetenv("TEST_VALUE")
+
+			obj := reflect.New(reflect.StructOf([]reflect.StructField{field}))
+
+			// Act
+			err := minienv.Load(obj.Interface())
+
+			// Assert
+			assert.Error(t, err)
+
+			loadErr := err.(minienv.LoadError)
+			assert.Equal(t, "Value", loadErr.Field)
+			assert.ErrorContains(t, loadErr, test.err)
+		})
+	}
+}
+
+func TestLoadWithChain(t *testing.T) {
+	// Arrange
+	type Credentials struct {
+		User     string `json:"user"`
+		Password string `json:"password"`
+	}
+
+	type S struct {
+		Credentials Credentials `env:"CREDENTIALS,chain=trim|base64|json"`
+		Token       string      `env:"TOKEN,chain=hex|upper"`
+	}
+
+	encoded := base64.StdEncoding.EncodeToString([]byte(`{"user":"admin","password":"secret"}`))
+	os.Setenv("CREDENTIALS", "  "+encoded+"\n")
+	defer os.Unsetenv("CREDENTIALS")
+
+	os.Setenv("TOKEN", hex.EncodeToString([]byte("abc")))
+	defer os.Unsetenv("TOKEN")
+
+	// Act
+	var s S
+	err := minienv.Load(&s)
+
+	// Assert
+	assert.Nil(t, err)
+	assert.Equal(t, Credentials{User: "admin", Password: "secret"}, s.Credentials)
+	assert.Equal(t, "ABC", s.Token)
+}
+
+func TestLoadWithChainAndFailingStage(t *testing.T) {
+	// Arrange
+	type S struct {
+		Value string `env:"TEST_VALUE,chain=trim|base64"`
+	}
+
+	os.Setenv("TEST_VALUE", "not base64!")
+	defer os.Unsetenv("TEST_VALUE")
+
+	// Act
+	var s S
+	err := minienv.Load(&s)
+
+	// Assert
+	assert.Error(t, err)
+	assert.ErrorContains(t, err, "chain stage base64 failed")
+}
+
+func TestLoadWithChainAndUnknownStage(t *testing.T) {
+	tests := map[string]string{
+		"unknown":        "trim|rot13",
+		"json not final": "json|trim",
+	}
+
+	for name, chain := range tests {
+		t.Run(name, func(t *testing.T) {
+			// Arrange
+			field := reflect.StructField{
+				Name: "Value",
+				Type: reflect.TypeOf(""),
+				Tag:  reflect.StructTag(fmt.Sprintf(`env:"TEST_VALUE,chain=%s"`, chain)),
+			}
+
+			obj := reflect.New(reflect.StructOf([]reflect.StructField{field}))
+
+			// Act
+			err := minienv.Load(obj.Interface())
+
+			// Assert
+			assert.Error(t, err)
+			assert.ErrorContains(t, err, "unknown chain stage")
+		})
+	}
+}
+
+type Settings struct {
+	Theme   string `json:"theme"`
+	Retries int    `json:"retries"`
+}
+
+func TestLoadWithJSONPointer(t *testing.T) {
+	// Arrange
+	type S struct {
+		Settings *Settings `env:"SETTINGS,json,optional"`
+		Absent   *Settings `env:"ABSENT,json,optional"`
+	}
+
+	os.Setenv("SETTINGS", `{"theme":"dark","retries":3}`)
+	defer os.Unsetenv("SETTINGS")
+
+	// Act
+	var s S
+	err := minienv.Load(&s)
+
+	// Assert
+	assert.Nil(t, err)
+	assert.Equal(t, &Settings{Theme: "dark", Retries: 3}, s.Settings)
+	assert.Nil(t, s.Absent)
+}
+
+func TestLoadWithJSONPointerAndInvalidJSON(t *testing.T) {
+	// Arrange
+	type S struct {
+		Settings *Settings `env:"SETTINGS,json,optional"`
+	}
+
+	os.Setenv("SETTINGS", `{"theme":`)
+	defer os.Unsetenv("SETTINGS")
+
+	// Act
+	var s S
+	err := minienv.Load(&s)
+
+	// Assert
+	assert.Error(t, err)
+
+	jsonErr := err.(minienv.LoadError)
+	assert.Equal(t, "Settings", jsonErr.Field)
+	assert.ErrorContains(t, jsonErr, "invalid JSON")
+}
+
+func TestLoadWithDefaultViolatingValidation(t *testing.T) {
+	tests := map[string]struct {
+		field reflect.StructField
+		err   string
+	}{
+		"format": {
+			field: reflect.StructField{Name: "Value", Type: reflect.TypeOf(""), Tag: `env:"TEST_VALUE,format=semver,default=latest"`},
+			err:   "value \"latest\" is not a valid semantic version",
+		},
+		"scale": {
+			field: reflect.StructField{Name: "Value", Type: reflect.TypeOf(0.0), Tag: `env:"TEST_VALUE,scale=2,default=1.234"`},
+			err:   "value \"1.234\" has 3 decimal places, but at most 2 are allowed",
+		},
+		"max": {
+			field: reflect.StructField{Name: "Value", Type: reflect.TypeOf(map[string]int{}), Tag: `env:"TEST_VALUE,max=1,default=a:1|b:2"`},
+			err:   "map has 2 entries, but at most 1 are allowed",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			// Arrange
+			obj := reflect.New(reflect.StructOf([]reflect.StructField{test.field}))
+
+			// Act
+			err := minienv.Load(obj.Interface())
+
+			// Assert
+			assert.Error(t, err)
+
+			defaultErr := err.(minienv.LoadError)
+			assert.Equal(t, "Value", defaultErr.Field)
+			assert.ErrorContains(t, defaultErr, test.err)
+		})
+	}
+}
+
+func TestLoadWithVerify(t *testing.T) {
+	// Arrange
+	type S struct {
+		Data string `env:"DATA,verify=sha256:DATA_SHA256"`
+	}
+
+	sum := sha256.Sum256([]byte("payload"))
+
+	os.Setenv("DATA", "payload")
+	defer os.Unsetenv("DATA")
+
+	os.Setenv("DATA_SHA256", strings.ToUpper(hex.EncodeToString(sum[:])))
+	defer os.Unsetenv("DATA_SHA256")
+
+	// Act
+	var s S
+	err := minienv.Load(&s)
+
+	// Assert
+	assert.Nil(t, err)
+	assert.Equal(t, "payload", s.Data)
+}
+
+func TestLoadWithVerifyAndMismatch(t *testing.T) {
+	// Arrange
+	type S struct {
+		Data string `env:"DATA,verify=sha256:DATA_SHA256"`
+	}
+
+	sum := sha256.Sum256([]byte("payload"))
+
+	os.Setenv("DATA", "tampered")
+	defer os.Unsetenv("DATA")
+
+	os.Setenv("DATA_SHA256", hex.EncodeToString(sum[:]))
+	defer os.Unsetenv("DATA_SHA256")
+
+	// Act
+	var s S
+	err := minienv.Load(&s)
+
+	// Assert
+	assert.Error(t, err)
+
+	verifyErr := err.(minienv.LoadError)
+	assert.Equal(t, "Data", verifyErr.Field)
+	assert.ErrorContains(t, verifyErr, "sha256 checksum of value does not match \"DATA_SHA256\"")
+}
+
+func TestLoadWithVerifyAndMissingChecksum(t *testing.T) {
+	// Arrange
+	type S struct {
+		Data string `env:"DATA,verify=sha256:DATA_SHA256"`
+	}
+
+	os.Setenv("DATA", "payload")
+	defer os.Unsetenv("DATA")
+
+	// Act
+	var s S
+	err := minienv.Load(&s)
+
+	// Assert
+	assert.Error(t, err)
+	assert.ErrorContains(t, err, "failed to read checksum from \"DATA_SHA256\"")
+}
+
+func TestLoadWithVerifyAndUnknownAlgorithm(t *testing.T) {
+	// Arrange
+	type S struct {
+		Data string `env:"DATA,verify=md5:DATA_MD5"`
+	}
+
+	// Act
+	var s S
+	err := minienv.Load(&s)
+
+	// Assert
+	assert.Error(t, err)
+	assert.ErrorContains(t, err, "unknown verify algorithm: md5")
+}
+
+func TestLoadPrefixed(t *testing.T) {
+	// Arrange
+	type Database struct {
+		Host     string `env:"HOST"`
+		Port     int    `env:"PORT,default=5432"`
+		Untagged string
+	}
+
+	os.Setenv("DB_HOST", "localhost")
+	defer os.Unsetenv("DB_HOST")
+
+	os.Setenv("HOST", "unprefixed")
+	defer os.Unsetenv("HOST")
+
+	// Act
+	var db Database
+	err := minienv.LoadPrefixed(&db, "DB_", minienv.WithFallbackValues(map[string]string{"DB_PORT": "6543"}))
+
+	// Assert
+	assert.Nil(t, err)
+	assert.Equal(t, Database{Host: "localhost", Port: 6543}, db)
+}
+
+func TestLoadPrefixedWithMissingValue(t *testing.T) {
+	// Arrange
+	type Database struct {
+		Host string `env:"HOST"`
+	}
+
+	os.Setenv("HOST", "unprefixed")
+	defer os.Unsetenv("HOST")
+
+	// Act
+	var db Database
+	err := minienv.LoadPrefixed(&db, "DB_")
+
+	// Assert
+	assert.Error(t, err)
+
+	loadErr := err.(minienv.LoadError)
+	assert.Equal(t, "Host", loadErr.Field)
+}
+
+func TestLoadPrefixedWithProviderError(t *testing.T) {
+	// Arrange
+	type Database struct {
+		Host string `env:"HOST"`
+	}
+
+	provider := func(ctx context.Context, key string) (string, bool, error) {
+		return "", false, errors.New("boom")
+	}
+
+	// Act
+	var db Database
+	err := minienv.LoadPrefixed(&db, "DB_", minienv.WithProvider(provider))
+
+	// Assert
+	assert.Error(t, err)
+	assert.ErrorContains(t, err, "provider lookup for \"HOST\" failed: boom")
+}
+
+func TestLoadPrefixedWithJoinError(t *testing.T) {
+	// Arrange
+	type Database struct {
+		Host string `env:"HOST,optional"`
+		Addr string `env:"ADDR,join=HOST|PORT,joinsep=:"`
+	}
+
+	// Act
+	var db Database
+	err := minienv.LoadPrefixed(&db, "DB_")
+
+	// Assert
+	assert.Error(t, err)
+	assert.ErrorContains(t, err, "join component \"HOST\" has no value")
+}
+
+func TestLoadPrefixedWithIndexedError(t *testing.T) {
+	// Arrange
+	type Database struct {
+		Replicas []Service `env:"REPLICAS,indexed=REPLICA_%d_"`
+	}
+
+	// Act
+	var db Database
+	err := minienv.LoadPrefixed(&db, "DB_")
+
+	// Assert
+	assert.Error(t, err)
+	assert.ErrorContains(t, err, "required field has no elements under \"REPLICA_0_\"")
+}
+
+func TestLoadPrefixedWithVerifyError(t *testing.T) {
+	// Arrange
+	type Database struct {
+		Password string `env:"PASSWORD,verify=sha256:PASSWORD_SHA256"`
+	}
+
+	os.Setenv("DB_PASSWORD", "tampered")
+	defer os.Unsetenv("DB_PASSWORD")
+
+	os.Setenv("DB_PASSWORD_SHA256", "00")
+	defer os.Unsetenv("DB_PASSWORD_SHA256")
+
+	// Act
+	var db Database
+	err := minienv.LoadPrefixed(&db, "DB_")
+
+	// Assert
+	assert.Error(t, err)
+	assert.ErrorContains(t, err, "sha256 checksum of value does not match \"PASSWORD_SHA256\"")
+}
+
+type Service struct {
+	Host string `env:"HOST"`
+	Port int    `env:"PORT,default=80"`
+}
+
+func TestLoadWithIndexed(t *testing.T) {
+	// Arrange
+	type S struct {
+		Services []Service `env:"SERVICES,indexed=SVC_%d_"`
+	}
+
+	os.Setenv("APP_SVC_0_HOST", "a.com")
+	defer os.Unsetenv("APP_SVC_0_HOST")
+
+	os.Setenv("APP_SVC_0_PORT", "8080")
+	defer os.Unsetenv("APP_SVC_0_PORT")
+
+	os.Setenv("APP_SVC_1_HOST", "b.com")
+	defer os.Unsetenv("APP_SVC_1_HOST")
+
+	// index 3 is not reached since index 2 is absent
+	os.Setenv("APP_SVC_3_HOST", "d.com")
+	defer os.Unsetenv("APP_SVC_3_HOST")
+
+	// Act
+	var s S
+	entries, err := minienv.LoadWithAudit(&s, minienv.WithPrefix("APP_"))
+
+	// Assert
+	assert.Nil(t, err)
+	assert.Equal(t, []Service{{Host: "a.com", Port: 8080}, {Host: "b.com", Port: 80}}, s.Services)
+	assert.Len(t, entries, 4)
+	assert.Equal(t, "APP_SVC_1_PORT", entries[3].Key)
+	assert.Equal(t, minienv.SourceDefault, entries[3].Source)
+}
+
+func TestLoadWithIndexedAndNoElements(t *testing.T) {
+	// Arrange
+	type S struct {
+		Services []Service `env:"SERVICES,indexed=SVC_%d_,optional"`
+	}
+
+	// Act
+	var s S
+	err := minienv.Load(&s)
+
+	// Assert
+	assert.Nil(t, err)
+	assert.Nil(t, s.Services)
+}
+
+func TestLoadWithIndexedAndNoRequiredElements(t *testing.T) {
+	// Arrange
+	type S struct {
+		Services []Service `env:"SERVICES,indexed=SVC_%d_"`
+	}
+
+	// Act
+	var s S
+	err := minienv.Load(&s)
+
+	// Assert
+	assert.Error(t, err)
+	assert.ErrorContains(t, err, "required field has no elements under \"SVC_0_\"")
+}
+
+func TestLoadWithIndexedAndPartialElement(t *testing.T) {
+	// Arrange
+	type S struct {
+		Services []Service `env:"SERVICES,indexed=SVC_%d_"`
+	}
+
+	os.Setenv("SVC_0_PORT", "8080")
+	defer os.Unsetenv("SVC_0_PORT")
+
+	// Act
+	var s S
+	err := minienv.Load(&s)
+
+	// Assert
+	assert.Error(t, err)
+	assert.ErrorContains(t, err, "failed to load element at index 0: struct is only partially configured, missing: SVC_0_HOST")
+}
+
+func TestLoadWithTimeLayout(t *testing.T) {
+	// Arrange
+	type S struct {
+		Start   time.Time `env:"START,layout=2006-01-02"`
+		Default time.Time `env:"DEFAULT"`
+	}
+
+	os.Setenv("START", "2023-01-02")
+	defer os.Unsetenv("START")
+
+	os.Setenv("DEFAULT", "2023-01-02T15:04:05Z")
+	defer os.Unsetenv("DEFAULT")
+
+	// Act
+	var s S
+	err := minienv.Load(&s)
+
+	// Assert
+	assert.Nil(t, err)
+	assert.Equal(t, time.Date(2023, 1, 2, 0, 0, 0, 0, time.UTC), s.Start)
+	assert.Equal(t, time.Date(2023, 1, 2, 15, 4, 5, 0, time.UTC), s.Default)
+}
+
+func TestLoadWithTimeLayoutAndMalformedValue(t *testing.T) {
+	// Arrange
+	type S struct {
+		Start time.Time `env:"START,layout=2006-01-02"`
+	}
+
+	os.Setenv("START", "2023-01-02T15:04:05Z")
+	defer os.Unsetenv("START")
+
+	// Act
+	var s S
+	err := minienv.Load(&s)
+
+	// Assert
+	assert.Error(t, err)
+
+	layoutErr := err.(minienv.LoadError)
+	assert.Equal(t, "Start", layoutErr.Field)
+	assert.ErrorContains(t, layoutErr, "invalid time \"2023-01-02T15:04:05Z\"")
+
+	var parseErr *time.ParseError
+	assert.ErrorAs(t, err, &parseErr)
+}
+
+func TestLoadWithTimeLayouts(t *testing.T) {
+	// Arrange
+	type S struct {
+		Date     time.Time `env:"DATE,layouts=2006-01-02|2006-01-02T15:04:05Z"`
+		DateTime time.Time `env:"DATE_TIME,layouts=2006-01-02|2006-01-02T15:04:05Z"`
+	}
+
+	os.Setenv("DATE", "2024-03-01")
+	defer os.Unsetenv("DATE")
+
+	os.Setenv("DATE_TIME", "2024-03-01T12:30:00Z")
+	defer os.Unsetenv("DATE_TIME")
+
+	// Act
+	var s S
+	err := minienv.Load(&s)
+
+	// Assert
+	assert.Nil(t, err)
+	assert.Equal(t, time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC), s.Date)
+	assert.Equal(t, time.Date(2024, 3, 1, 12, 30, 0, 0, time.UTC), s.DateTime)
+}
+
+func TestLoadWithTimeLayoutsAndNoMatch(t *testing.T) {
+	// Arrange
+	type S struct {
+		Date time.Time `env:"DATE,layouts=2006-01-02|2006-01-02T15:04:05Z"`
+	}
+
+	os.Setenv("DATE", "01.03.2024")
+	defer os.Unsetenv("DATE")
+
+	// Act
+	var s S
+	err := minienv.Load(&s)
+
+	// Assert
+	assert.Error(t, err)
+
+	layoutErr := err.(minienv.LoadError)
+	assert.Equal(t, "Date", layoutErr.Field)
+	assert.ErrorContains(t, layoutErr, "value \"01.03.2024\" matches none of the layouts 2006-01-02, 2006-01-02T15:04:05Z")
+}
+
+func TestLoadWithAbsPath(t *testing.T) {
+	// Arrange
+	type S struct {
+		Relative string `env:"RELATIVE,abspath"`
+		Absolute string `env:"ABSOLUTE,abspath"`
+		Optional string `env:"OPTIONAL,abspath,optional"`
+	}
+
+	wd, err := os.Getwd()
+	assert.Nil(t, err)
+
+	absolute := filepath.Join(wd, "data")
+
+	os.Setenv("RELATIVE", "./data")
+	defer os.Unsetenv("RELATIVE")
+
+	os.Setenv("ABSOLUTE", absolute+"/nested/../")
+	defer os.Unsetenv("ABSOLUTE")
+
+	// Act
+	var s S
+	err = minienv.Load(&s)
+
+	// Assert
+	assert.Nil(t, err)
+	assert.Equal(t, absolute, s.Relative)
+	assert.Equal(t, absolute, s.Absolute)
+	assert.Equal(t, "", s.Optional)
+}
+
+func TestLoadWithCount(t *testing.T) {
+	// Arrange
+	type S struct {
+		Verbose int `env:"VERBOSE,count=v"`
+		Flags   int `env:"FLAGS,count=-v"`
+		Empty   int `env:"EMPTY,count=v,optional"`
+	}
+
+	os.Setenv("VERBOSE", "vvv")
+	defer os.Unsetenv("VERBOSE")
+
+	os.Setenv("FLAGS", "-v -v -x")
+	defer os.Unsetenv("FLAGS")
+
+	// Act
+	var s S
+	err := minienv.Load(&s)
+
+	// Assert
+	assert.Nil(t, err)
+	assert.Equal(t, 3, s.Verbose)
+	assert.Equal(t, 2, s.Flags)
+	assert.Equal(t, 0, s.Empty)
+}
+
+func TestLoadWithCountOnInvalidType(t *testing.T) {
+	// Arrange
+	type S struct {
+		Verbose string `env:"VERBOSE,count=v"`
+	}
+
+	// Act
+	var s S
+	err := minienv.Load(&s)
+
+	// Assert
+	assert.Error(t, err)
+	assert.ErrorContains(t, err, "count can only be used on int fields")
+}
+
+func TestLoadWithErrorsField(t *testing.T) {
+	// Arrange
+	type Database struct {
+		Port int `env:"DB_PORT"`
+	}
+
+	type S struct {
+		Host     string `env:"HOST"`
+		Port     int    `env:"PORT"`
+		Timeout  int    `env:"TIMEOUT,default=30"`
+		Database Database
+		Errs     error `env:",errors"`
+	}
+
+	os.Setenv("PORT", "not-a-number")
+	defer os.Unsetenv("PORT")
+
+	os.Setenv("DB_PORT", "5432")
+	defer os.Unsetenv("DB_PORT")
+
+	// Act
+	var s S
+	err := minienv.Load(&s)
+
+	// Assert
+	assert.Nil(t, err)
+	assert.Equal(t, 30, s.Timeout)
+	assert.Equal(t, 5432, s.Database.Port)
+
+	assert.Error(t, s.Errs)
+	assert.ErrorContains(t, s.Errs, "failed to load field \"Host\"")
+	assert.ErrorContains(t, s.Errs, "failed to load field \"Port\"")
+
+	var loadErr minienv.LoadError
+	assert.ErrorAs(t, s.Errs, &loadErr)
+}
+
+func TestLoadWithErrorsFieldAndNoErrors(t *testing.T) {
+	// Arrange
+	type S struct {
+		Timeout int   `env:"TIMEOUT,default=30"`
+		Errs    error `env:",errors"`
+	}
+
+	// Act
+	var s S
+	err := minienv.Load(&s)
+
+	// Assert
+	assert.Nil(t, err)
+	assert.Nil(t, s.Errs)
+}
+
+func TestLoadWithErrorsFieldAndInvalidInput(t *testing.T) {
+	// Arrange
+	type S struct {
+		Errs error `env:",errors"`
+	}
+
+	// Act
+	var s S
+	err := minienv.Load(s)
+
+	// Assert
+	assert.ErrorIs(t, err, minienv.ErrInvalidInput)
+}
+
+func TestLoadWithErrorsOnInvalidType(t *testing.T) {
+	// Arrange
+	type S struct {
+		Errs string `env:",errors"`
+	}
+
+	// Act
+	var s S
+	err := minienv.Load(&s)
+
+	// Assert
+	assert.Error(t, err)
+	assert.ErrorContains(t, err, "errors can only be used on error fields")
+}
+
+func TestLoadWithAgreeingConsistencyGroup(t *testing.T) {
+	// Arrange
+	type Storage struct {
+		Region string `env:"STORAGE_REGION,consistency=region"`
+	}
+
+	type S struct {
+		Region  string  `env:"REGION,consistency=region"`
+		Backup  string  `env:"BACKUP_REGION,optional,consistency=region"`
+		Storage Storage `env:"STORAGE"`
+	}
+
+	os.Setenv("REGION", "eu-west-1")
+	defer os.Unsetenv("REGION")
+
+	os.Setenv("STORAGE_REGION", "eu-west-1")
+	defer os.Unsetenv("STORAGE_REGION")
+
+	// Act
+	var s S
+	err := minienv.Load(&s)
+
+	// Assert
+	assert.Nil(t, err)
+	assert.Equal(t, "eu-west-1", s.Region)
+	assert.Equal(t, "", s.Backup)
+	assert.Equal(t, "eu-west-1", s.Storage.Region)
+}
+
+func TestLoadWithDisagreeingConsistencyGroup(t *testing.T) {
+	// Arrange
+	type S struct {
+		Region       string `env:"REGION,consistency=region"`
+		BucketRegion string `env:"BUCKET_REGION,default=us-east-1,consistency=region"`
+	}
+
+	os.Setenv("REGION", "eu-west-1")
+	defer os.Unsetenv("REGION")
+
+	// Act
+	var s S
+	err := minienv.Load(&s)
+
+	// Assert
+	assert.Error(t, err)
+	assert.ErrorContains(t, err, "consistency group \"region\" has conflicting values")
+	assert.ErrorContains(t, err, "Region (REGION) = \"eu-west-1\"")
+	assert.ErrorContains(t, err, "BucketRegion (BUCKET_REGION) = \"us-east-1\"")
+}
+
+func TestLoadWithMissingConsistencyGroup(t *testing.T) {
+	// Arrange
+	type S struct {
+		Region string `env:"REGION,optional,consistency"`
+	}
+
+	// Act
+	var s S
+	err := minienv.Load(&s)
+
+	// Assert
+	assert.Error(t, err)
+	assert.ErrorContains(t, err, "invalid consistency tag")
+}
+
+func TestLoadWithPointers(t *testing.T) {
+	// Arrange
+	type S struct {
+		Int      *int           `env:"TEST_INT"`
+		Nested   **string       `env:"TEST_STRING"`
+		Duration *time.Duration `env:"TEST_DURATION"`
+		Money    *Money         `env:"TEST_MONEY"`
+		Ints     []*int         `env:"TEST_INTS"`
+	}
+
+	os.Setenv("TEST_INT", "0")
+	defer os.Unsetenv("TEST_INT")
+
+	os.Setenv("TEST_STRING", "value")
+	defer os.Unsetenv("TEST_STRING")
+
+	os.Setenv("TEST_DURATION", "5s")
+	defer os.Unsetenv("TEST_DURATION")
+
+	os.Setenv("TEST_MONEY", "1.25")
+	defer os.Unsetenv("TEST_MONEY")
+
+	os.Setenv("TEST_INTS", "1|2")
+	defer os.Unsetenv("TEST_INTS")
+
+	// Act
+	var s S
+	err := minienv.Load(&s)
+
+	// Assert
+	assert.Nil(t, err)
+	assert.NotNil(t, s.Int)
+	assert.Equal(t, 0, *s.Int)
+	assert.Equal(t, "value", **s.Nested)
+	assert.Equal(t, 5*time.Second, *s.Duration)
+	assert.Equal(t, Money{Cents: 125}, *s.Money)
+	assert.Len(t, s.Ints, 2)
+	assert.Equal(t, 2, *s.Ints[1])
+}
+
+func TestLoadWithUnsetOptionalPointers(t *testing.T) {
+	// Arrange
+	type S struct {
+		Int    *int   `env:"TEST_INT,optional"`
+		Nested **bool `env:"TEST_BOOL,optional"`
+	}
+
+	// Act
+	var s S
+	err := minienv.Load(&s)
+
+	// Assert
+	assert.Nil(t, err)
+	assert.Nil(t, s.Int)
+	assert.Nil(t, s.Nested)
+}
+
+func TestLoadWithPointerDefault(t *testing.T) {
+	// Arrange
+	type S struct {
+		Value *int `env:"TEST_VALUE,default=5"`
+	}
+
+	// Act
+	var s S
+	err := minienv.Load(&s)
+
+	// Assert
+	assert.Nil(t, err)
+	assert.Equal(t, 5, *s.Value)
+}
+
+func TestLoadWithInvalidPointerValue(t *testing.T) {
+	// Arrange
+	type S struct {
+		Value *int `env:"TEST_VALUE"`
+	}
+
+	os.Setenv("TEST_VALUE", "abc")
+	defer os.Unsetenv("TEST_VALUE")
+
+	// Act
+	var s S
+	err := minienv.Load(&s)
+
+	// Assert
+	assert.Error(t, err)
+
+	loadErr := err.(minienv.LoadError)
+	assert.Equal(t, "Value", loadErr.Field)
+	assert.Nil(t, s.Value)
+}
+
+func TestLoadWithNetworks(t *testing.T) {
+	// Arrange
+	type S struct {
+		BindIP   net.IP      `env:"BIND_IP"`
+		Subnet   net.IPNet   `env:"SUBNET"`
+		Optional *net.IPNet  `env:"OPTIONAL_SUBNET,optional"`
+		Allowed  []net.IPNet `env:"ALLOWED"`
+	}
+
+	os.Setenv("BIND_IP", "10.0.0.5")
+	defer os.Unsetenv("BIND_IP")
+
+	os.Setenv("SUBNET", "10.0.0.0/24")
+	defer os.Unsetenv("SUBNET")
+
+	os.Setenv("ALLOWED", "192.168.0.0/16|fd00::/8")
+	defer os.Unsetenv("ALLOWED")
+
+	// Act
+	var s S
+	err := minienv.Load(&s)
+
+	// Assert
+	assert.Nil(t, err)
+	assert.True(t, s.BindIP.Equal(net.ParseIP("10.0.0.5")))
+	assert.Equal(t, "10.0.0.0/24", s.Subnet.String())
+	assert.Nil(t, s.Optional)
+	assert.Len(t, s.Allowed, 2)
+	assert.Equal(t, "192.168.0.0/16", s.Allowed[0].String())
+	assert.Equal(t, "fd00::/8", s.Allowed[1].String())
+}
+
+func TestLoadWithInvalidNetwork(t *testing.T) {
+	// Arrange
+	type S struct {
+		Subnet net.IPNet `env:"SUBNET"`
+	}
+
+	os.Setenv("SUBNET", "10.0.0.0")
+	defer os.Unsetenv("SUBNET")
+
+	// Act
+	var s S
+	err := minienv.Load(&s)
+
+	// Assert
+	assert.Error(t, err)
+
+	loadErr := err.(minienv.LoadError)
+	assert.Equal(t, "Subnet", loadErr.Field)
+	assert.ErrorContains(t, loadErr, "invalid network \"10.0.0.0\"")
+}
+
+func TestLoadWithStripComment(t *testing.T) {
+	// Arrange
+	type S struct {
+		Commented string `env:"COMMENTED,stripcomment"`
+		Plain     string `env:"PLAIN,stripcomment"`
+		Quoted    string `env:"QUOTED,stripcomment"`
+		Number    int    `env:"NUMBER,stripcomment"`
+		Kept      string `env:"KEPT"`
+	}
+
+	os.Setenv("COMMENTED", "foo # the name")
+	defer os.Unsetenv("COMMENTED")
+
+	os.Setenv("PLAIN", "bar")
+	defer os.Unsetenv("PLAIN")
+
+	os.Setenv("QUOTED", "\"a # b\" 'c#d' # comment")
+	defer os.Unsetenv("QUOTED")
+
+	os.Setenv("NUMBER", "42\t# answer")
+	defer os.Unsetenv("NUMBER")
+
+	os.Setenv("KEPT", "foo # bar")
+	defer os.Unsetenv("KEPT")
+
+	// Act
+	var s S
+	err := minienv.Load(&s)
+
+	// Assert
+	assert.Nil(t, err)
+	assert.Equal(t, "foo", s.Commented)
+	assert.Equal(t, "bar", s.Plain)
+	assert.Equal(t, "\"a # b\" 'c#d'", s.Quoted)
+	assert.Equal(t, 42, s.Number)
+	assert.Equal(t, "foo # bar", s.Kept)
+}
+
+func TestLoadWithStripCommentFromFallback(t *testing.T) {
+	// Arrange
+	type S struct {
+		Value string `env:"TEST_VALUE,stripcomment"`
+	}
+
+	// Act
+	var s S
+	err := minienv.Load(&s, minienv.WithFallbackValues(map[string]string{"TEST_VALUE": "value #comment"}))
+
+	// Assert
+	assert.Nil(t, err)
+	assert.Equal(t, "value", s.Value)
+}
+
+type Backend struct {
+	Name   string
+	Weight int
+}
+
+func TestLoadWithWeighted(t *testing.T) {
+	// Arrange
+	type S struct {
+		Backends []Backend `env:"BACKENDS,weighted"`
+	}
+
+	os.Setenv("BACKENDS", "a=3|b=1|c=2")
+	defer os.Unsetenv("BACKENDS")
+
+	// Act
+	var s S
+	err := minienv.Load(&s)
+
+	// Assert
+	assert.Nil(t, err)
+	assert.Equal(t, []Backend{{"a", 3}, {"b", 1}, {"c", 2}}, s.Backends)
+}
+
+func TestLoadWithNonIntegerWeight(t *testing.T) {
+	// Arrange
+	type S struct {
+		Backends []Backend `env:"BACKENDS,weighted"`
+	}
+
+	os.Setenv("BACKENDS", "a=3|b=heavy")
+	defer os.Unsetenv("BACKENDS")
+
+	// Act
+	var s S
+	err := minienv.Load(&s)
+
+	// Assert
+	assert.Error(t, err)
+
+	loadErr := err.(minienv.LoadError)
+	assert.Equal(t, "Backends", loadErr.Field)
+	assert.ErrorContains(t, loadErr, "invalid weight in entry \"b=heavy\"")
+}
+
+func TestLoadWithWeightedOnInvalidType(t *testing.T) {
+	// Arrange
+	type S struct {
+		Backends []string `env:"BACKENDS,weighted"`
+	}
+
+	// Act
+	var s S
+	err := minienv.Load(&s)
+
+	// Assert
+	assert.Error(t, err)
+	assert.ErrorContains(t, err, "weighted can only be used on slices of structs")
+}
+
+func TestLoadWithBase64(t *testing.T) {
+	// Arrange
+	type S struct {
+		Text    string `env:"TEST_TEXT,base64"`
+		Bytes   []byte `env:"TEST_BYTES,base64"`
+		Default string `env:"TEST_DEFAULT,base64,default=aGk="`
+	}
+
+	os.Setenv("TEST_TEXT", base64.StdEncoding.EncodeToString([]byte("secret")))
+	defer os.Unsetenv("TEST_TEXT")
+
+	os.Setenv("TEST_BYTES", base64.StdEncoding.EncodeToString([]byte{0x00, 0xff, 0x10}))
+	defer os.Unsetenv("TEST_BYTES")
+
+	// Act
+	var s S
+	err := minienv.Load(&s)
+
+	// Assert
+	assert.Nil(t, err)
+	assert.Equal(t, "secret", s.Text)
+	assert.Equal(t, []byte{0x00, 0xff, 0x10}, s.Bytes)
+	assert.Equal(t, "hi", s.Default)
+}
+
+func TestLoadWithInvalidBase64(t *testing.T) {
+	// Arrange
+	type S struct {
+		Value string `env:"TEST_VALUE,base64"`
+	}
+
+	os.Setenv("TEST_VALUE", "not base64!")
+	defer os.Unsetenv("TEST_VALUE")
+
+	// Act
+	var s S
+	err := minienv.Load(&s)
+
+	// Assert
+	assert.Error(t, err)
+
+	loadErr := err.(minienv.LoadError)
+	assert.Equal(t, "Value", loadErr.Field)
+	assert.ErrorContains(t, loadErr, "invalid base64 value")
+}
+
+func TestLoadWithBase64OnInvalidType(t *testing.T) {
+	// Arrange
+	type S struct {
+		Value int `env:"TEST_VALUE,base64"`
+	}
+
+	// Act
+	var s S
+	err := minienv.Load(&s)
+
+	// Assert
+	assert.Error(t, err)
+	assert.ErrorContains(t, err, "base64 can only be used on string and []byte fields")
+}
+
+type DefaultableEnv struct {
+	Host string `env:"TEST_HOST"`
+	Port int    `env:"TEST_PORT,default=80"`
+	Name string `env:"TEST_NAME,optional"`
+}
+
+func (e *DefaultableEnv) SetDefaults() {
+	e.Host = "localhost"
+	e.Port = 8080
+}
+
+func TestLoadWithDefaultableOverriddenByEnv(t *testing.T) {
+	// Arrange
+	os.Setenv("TEST_HOST", "example.com")
+	defer os.Unsetenv("TEST_HOST")
+
+	// Act
+	var e DefaultableEnv
+	err := minienv.Load(&e)
+
+	// Assert
+	assert.Nil(t, err)
+	assert.Equal(t, "example.com", e.Host)
+	assert.Equal(t, 8080, e.Port)
+	assert.Equal(t, "", e.Name)
+}
+
+func TestLoadWithDefaultableWithoutEnv(t *testing.T) {
+	// Act
+	var e DefaultableEnv
+	err := minienv.Load(&e)
+
+	// Assert
+	assert.Nil(t, err)
+	assert.Equal(t, "localhost", e.Host)
+	assert.Equal(t, 8080, e.Port)
+}
+
+func TestLoadWithUnitConversion(t *testing.T) {
+	// Arrange
+	type S struct {
+		Timeout int     `env:"TIMEOUT,in=seconds,store=ms"`
+		Limit   uint64  `env:"LIMIT,in=mib,store=b"`
+		Hours   float64 `env:"HOURS,in=m,store=h"`
+		Delay   int     `env:"DELAY,in=ms,store=s,default=3000"`
+	}
+
+	os.Setenv("TIMEOUT", "5")
+	defer os.Unsetenv("TIMEOUT")
+
+	os.Setenv("LIMIT", "2")
+	defer os.Unsetenv("LIMIT")
+
+	os.Setenv("HOURS", "90")
+	defer os.Unsetenv("HOURS")
+
+	// Act
+	var s S
+	err := minienv.Load(&s)
+
+	// Assert
+	assert.Nil(t, err)
+	assert.Equal(t, 5000, s.Timeout)
+	assert.Equal(t, uint64(2<<20), s.Limit)
+	assert.Equal(t, 1.5, s.Hours)
+	assert.Equal(t, 3, s.Delay)
+}
+
+func TestLoadWithUnitConversionToFraction(t *testing.T) {
+	// Arrange
+	type S struct {
+		Value int `env:"TEST_VALUE,in=ms,store=s"`
+	}
+
+	os.Setenv("TEST_VALUE", "1500")
+	defer os.Unsetenv("TEST_VALUE")
+
+	// Act
+	var s S
+	err := minienv.Load(&s)
+
+	// Assert
+	assert.Error(t, err)
+	assert.ErrorContains(t, err, "\"1500\" is not a whole number after the unit conversion")
+}
+
+func TestLoadWithUnsupportedUnitConversion(t *testing.T) {
+	// Arrange
+	type S struct {
+		Value int `env:"TEST_VALUE,in=seconds,store=mb"`
+	}
+
+	// Act
+	var s S
+	err := minienv.Load(&s)
+
+	// Assert
+	assert.Error(t, err)
+
+	tagParseErr := err.(minienv.LoadError)
+	assert.Equal(t, "Value", tagParseErr.Field)
+	assert.ErrorContains(t, tagParseErr, "unsupported unit conversion: seconds to mb")
+}
+
+func TestLoadWithIncompleteUnitConversion(t *testing.T) {
+	// Arrange
+	type S struct {
+		Value int `env:"TEST_VALUE,in=seconds"`
+	}
+
+	// Act
+	var s S
+	err := minienv.Load(&s)
+
+	// Assert
+	assert.Error(t, err)
+	assert.ErrorContains(t, err, "in and store have to be used together")
+}
+
+func TestLoadWithRawBytes(t *testing.T) {
+	// Arrange
+	type S struct {
+		Secret  []byte `env:"SECRET"`
+		Default []byte `env:"DEFAULT,default=a|b"`
+	}
+
+	os.Setenv("SECRET", "s3cr3t|1,2")
+	defer os.Unsetenv("SECRET")
+
+	// Act
+	var s S
+	err := minienv.Load(&s)
+
+	// Assert
+	assert.Nil(t, err)
+	assert.Equal(t, []byte("s3cr3t|1,2"), s.Secret)
+	assert.Equal(t, []byte("a|b"), s.Default)
+}
+
+func TestLoadWithNumericBytes(t *testing.T) {
+	// Arrange
+	type S struct {
+		Values []uint8 `env:"TEST_VALUES,split=|"`
+	}
+
+	os.Setenv("TEST_VALUES", "1|2|255")
+	defer os.Unsetenv("TEST_VALUES")
+
+	// Act
+	var s S
+	err := minienv.Load(&s)
+
+	// Assert
+	assert.Nil(t, err)
+	assert.Equal(t, []uint8{1, 2, 255}, s.Values)
+}
+
+func TestLoadWithClamp(t *testing.T) {
+	// Arrange
+	type S struct {
+		Below  int     `env:"BELOW,min=1,max=10,clamp"`
+		Above  uint    `env:"ABOVE,min=1,max=10,clamp"`
+		Within float64 `env:"WITHIN,min=0.5,max=1.5,clamp"`
+		Ratio  float64 `env:"RATIO,max=1,clamp"`
+	}
+
+	os.Setenv("BELOW", "-5")
+	defer os.Unsetenv("BELOW")
+
+	os.Setenv("ABOVE", "50")
+	defer os.Unsetenv("ABOVE")
+
+	os.Setenv("WITHIN", "0.75")
+	defer os.Unsetenv("WITHIN")
+
+	os.Setenv("RATIO", "1.25")
+	defer os.Unsetenv("RATIO")
+
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&buf, nil))
+
+	// Act
+	var s S
+	err := minienv.Load(&s, minienv.WithLogger(logger))
+
+	// Assert
+	assert.Nil(t, err)
+	assert.Equal(t, 1, s.Below)
+	assert.Equal(t, uint(10), s.Above)
+	assert.Equal(t, 0.75, s.Within)
+	assert.Equal(t, 1.0, s.Ratio)
+	assert.Contains(t, buf.String(), "key=BELOW")
+	assert.NotContains(t, buf.String(), "key=WITHIN")
+}
+
+func TestLoadWithBoundsWithoutClamp(t *testing.T) {
+	// Arrange
+	type S struct {
+		Value int `env:"TEST_VALUE,min=1,max=10"`
+	}
+
+	os.Setenv("TEST_VALUE", "11")
+	defer os.Unsetenv("TEST_VALUE")
+
+	// Act
+	var s S
+	err := minienv.Load(&s)
+
+	// Assert
+	assert.Error(t, err)
+
+	loadErr := err.(minienv.LoadError)
+	assert.Equal(t, "Value", loadErr.Field)
+	assert.ErrorContains(t, loadErr, "value 11 is greater than the maximum of 10")
+}
+
+func TestLoadWithClampWithoutBounds(t *testing.T) {
+	// Arrange
+	type S struct {
+		Value int `env:"TEST_VALUE,clamp"`
+	}
+
+	// Act
+	var s S
+	err := minienv.Load(&s)
+
+	// Assert
+	assert.Error(t, err)
+	assert.ErrorContains(t, err, "clamp can only be used together with min or max")
+}
+
+func TestLoadWithAliases(t *testing.T) {
+	// Arrange
+	type S struct {
+		Primary string `env:"DATABASE_URL,aliases=OLD_DB_URL|LEGACY_URL"`
+		Alias   string `env:"CACHE_URL,aliases=OLD_CACHE_URL|LEGACY_CACHE_URL"`
+		File    string `env:"QUEUE_URL,aliases=OLD_QUEUE_URL"`
+		Default string `env:"SEARCH_URL,aliases=OLD_SEARCH_URL,default=local"`
+	}
+
+	os.Setenv("DATABASE_URL", "primary")
+	defer os.Unsetenv("DATABASE_URL")
+
+	os.Setenv("OLD_DB_URL", "old")
+	defer os.Unsetenv("OLD_DB_URL")
+
+	os.Setenv("LEGACY_CACHE_URL", "legacy")
+	defer os.Unsetenv("LEGACY_CACHE_URL")
+
+	// Act
+	var s S
+	err := minienv.Load(&s, minienv.WithFallbackValues(map[string]string{"OLD_QUEUE_URL": "fallback"}))
+
+	// Assert
+	assert.Nil(t, err)
+	assert.Equal(t, "primary", s.Primary)
+	assert.Equal(t, "legacy", s.Alias)
+	assert.Equal(t, "fallback", s.File)
+	assert.Equal(t, "local", s.Default)
+}
+
+func TestLoadWithAliasesAndPrefix(t *testing.T) {
+	// Arrange
+	type S struct {
+		Value string `env:"DATABASE_URL,aliases=OLD_DB_URL"`
+	}
+
+	os.Setenv("APP_OLD_DB_URL", "old")
+	defer os.Unsetenv("APP_OLD_DB_URL")
+
+	// Act
+	var s S
+	err := minienv.Load(&s, minienv.WithPrefix("APP_"))
+
+	// Assert
+	assert.Nil(t, err)
+	assert.Equal(t, "old", s.Value)
+}
+
+func TestLoadWithMissingAliases(t *testing.T) {
+	// Arrange
+	type S struct {
+		Value string `env:"DATABASE_URL,aliases=OLD_DB_URL"`
+	}
+
+	// Act
+	var s S
+	err := minienv.Load(&s)
+
+	// Assert
+	assert.Error(t, err)
+
+	loadErr := err.(minienv.LoadError)
+	assert.Equal(t, "Value", loadErr.Field)
+	assert.ErrorContains(t, loadErr, "required field has no value")
+}
+
+func TestLoadWithExplicitRequired(t *testing.T) {
+	// Arrange
+	type S struct {
+		Value   string `env:"TEST_VALUE,required"`
+		Missing string `env:"TEST_MISSING,required"`
+	}
+
+	os.Setenv("TEST_VALUE", "value")
+	defer os.Unsetenv("TEST_VALUE")
+
+	// Act
+	var s S
+	err := minienv.Load(&s)
+
+	// Assert
+	assert.Error(t, err)
+
+	loadErr := err.(minienv.LoadError)
+	assert.Equal(t, "Missing", loadErr.Field)
+	assert.ErrorContains(t, loadErr, "required field has no value and no default")
+	assert.Equal(t, "value", s.Value)
+}
+
+func TestLoadWithRequiredAndOptional(t *testing.T) {
+	// Arrange
+	type S struct {
+		Value string `env:"TEST_VALUE,optional,required"`
+	}
+
+	// Act
+	var s S
+	err := minienv.Load(&s)
+
+	// Assert
+	assert.Error(t, err)
+	assert.ErrorContains(t, err, "optional and required can't be used together")
+}
+
+func TestLoadWithInclusiveBounds(t *testing.T) {
+	// Arrange
+	type S struct {
+		Min   int     `env:"TEST_MIN,min=1,max=64"`
+		Max   uint8   `env:"TEST_MAX,min=1,max=64"`
+		Float float32 `env:"TEST_FLOAT,min=0.5,max=1.5"`
+	}
+
+	os.Setenv("TEST_MIN", "1")
+	defer os.Unsetenv("TEST_MIN")
+
+	os.Setenv("TEST_MAX", "64")
+	defer os.Unsetenv("TEST_MAX")
+
+	os.Setenv("TEST_FLOAT", "1.5")
+	defer os.Unsetenv("TEST_FLOAT")
+
+	// Act
+	var s S
+	err := minienv.Load(&s)
+
+	// Assert
+	assert.Nil(t, err)
+	assert.Equal(t, 1, s.Min)
+	assert.Equal(t, uint8(64), s.Max)
+	assert.Equal(t, float32(1.5), s.Float)
+}
+
+func TestLoadWithValueBelowMinimum(t *testing.T) {
+	tests := map[string]interface{}{
+		"uint": &struct {
+			Value uint `env:"TEST_VALUE,min=5"`
+		}{},
+		"float": &struct {
+			Value float64 `env:"TEST_VALUE,min=5"`
+		}{},
+	}
+
+	for name, obj := range tests {
+		t.Run(name, func(t *testing.T) {
+			// Arrange
+			os.Setenv("TEST_VALUE", "4")
+			defer os.Unsetenv("TEST_VALUE")
+
+			// Act
+			err := minienv.Load(obj)
+
+			// Assert
+			assert.Error(t, err)
+			assert.ErrorContains(t, err, "value 4 is less than the minimum of 5")
+		})
+	}
+}
+
+func TestLoadWithInvalidBound(t *testing.T) {
+	// Arrange
+	type S struct {
+		Value int `env:"TEST_VALUE,min=1.5"`
+	}
+
+	// Act
+	var s S
+	err := minienv.Load(&s)
+
+	// Assert
+	assert.Error(t, err)
+	assert.ErrorContains(t, err, "invalid min tag: 1.5")
+}
+
+func TestLoadWithOneOf(t *testing.T) {
+	// Arrange
+	type S struct {
+		Level   string `env:"LOG_LEVEL,oneof=debug|info|warn|error"`
+		Version int    `env:"VERSION,oneof=1|2"`
+		Default string `env:"MODE,oneof=dev|prod,default=dev"`
+	}
+
+	os.Setenv("LOG_LEVEL", "warn")
+	defer os.Unsetenv("LOG_LEVEL")
+
+	os.Setenv("VERSION", "02")
+	defer os.Unsetenv("VERSION")
+
+	// Act
+	var s S
+	err := minienv.Load(&s)
+
+	// Assert
+	assert.Nil(t, err)
+	assert.Equal(t, "warn", s.Level)
+	assert.Equal(t, 2, s.Version)
+	assert.Equal(t, "dev", s.Default)
+}
+
+func TestLoadWithValueNotOneOf(t *testing.T) {
+	// Arrange
+	type S struct {
+		Level string `env:"LOG_LEVEL,oneof=debug|info|warn|error"`
+	}
+
+	os.Setenv("LOG_LEVEL", "verbose")
+	defer os.Unsetenv("LOG_LEVEL")
+
+	// Act
+	var s S
+	err := minienv.Load(&s)
+
+	// Assert
+	assert.Error(t, err)
+
+	loadErr := err.(minienv.LoadError)
+	assert.Equal(t, "Level", loadErr.Field)
+	assert.ErrorContains(t, loadErr, "value \"verbose\" is not one of the allowed values: debug, info, warn, error")
+}
+
+func TestLoadWithDefaultNotOneOf(t *testing.T) {
+	// Arrange
+	type S struct {
+		Mode string `env:"MODE,oneof=dev|prod,default=test"`
+	}
+
+	// Act
+	var s S
+	err := minienv.Load(&s)
+
+	// Assert
+	assert.Error(t, err)
+	assert.ErrorContains(t, err, "value \"test\" is not one of the allowed values: dev, prod")
+}
+
+func TestLoadWithOneOfOnInvalidType(t *testing.T) {
+	// Arrange
+	type S struct {
+		Value bool `env:"TEST_VALUE,oneof=true"`
+	}
+
+	// Act
+	var s S
+	err := minienv.Load(&s)
+
+	// Assert
+	assert.Error(t, err)
+	assert.ErrorContains(t, err, "oneof can only be used on string and int fields")
+}
+
+func TestLoadWithRegex(t *testing.T) {
+	// Arrange
+	type S struct {
+		Version string `env:"VERSION,regex=^v\\d+\\.\\d+\\.\\d+$"`
+		Code    int    `env:"CODE,default=42,regex=^\\d{1,3}$"`
+		Pair    string `env:"PAIR,optional,regex=^[a-z]+,[a-z]+$"`
+	}
+
+	os.Setenv("VERSION", "v1.2.3")
+	defer os.Unsetenv("VERSION")
+
+	os.Setenv("PAIR", "a,b")
+	defer os.Unsetenv("PAIR")
+
+	// Act
+	var s S
+	err := minienv.Load(&s)
+
+	// Assert
+	assert.Nil(t, err)
+	assert.Equal(t, "v1.2.3", s.Version)
+	assert.Equal(t, 42, s.Code)
+	assert.Equal(t, "a,b", s.Pair)
+}
+
+func TestLoadWithRegexMismatch(t *testing.T) {
+	// Arrange
+	type S struct {
+		Version string `env:"VERSION,regex=^v\\d+\\.\\d+\\.\\d+$"`
+	}
+
+	os.Setenv("VERSION", "1.2")
+	defer os.Unsetenv("VERSION")
+
+	// Act
+	var s S
+	err := minienv.Load(&s)
+
+	// Assert
+	assert.Error(t, err)
+
+	loadErr := err.(minienv.LoadError)
+	assert.Equal(t, "Version", loadErr.Field)
+	assert.ErrorContains(t, loadErr, "value \"1.2\" does not match the pattern \"^v\\d+\\.\\d+\\.\\d+$\"")
+}
+
+func TestLoadWithInvalidRegex(t *testing.T) {
+	// Arrange
+	type S struct {
+		Value string `env:"TEST_VALUE,regex=^(a"`
+	}
+
+	// Act
+	var s S
+	err := minienv.Load(&s)
+
+	// Assert
+	assert.Error(t, err)
+
+	tagParseErr := err.(minienv.LoadError)
+	assert.Equal(t, "Value", tagParseErr.Field)
+	assert.ErrorContains(t, tagParseErr, "invalid regex tag")
+}
+
+func TestLoadWithQuotedRegex(t *testing.T) {
+	// Arrange
+	type S struct {
+		Pair  string `env:"PAIR,regex='^[a-z]+,[a-z]+$',optional"`
+		Code  string `env:"CODE,regex='^a$',optional"`
+		Value string `env:"VALUE,regex='^[0-9]{1,3}$',default=12"`
+	}
+
+	os.Setenv("PAIR", "a,b")
+	defer os.Unsetenv("PAIR")
+
+	// Act
+	var s S
+	err := minienv.Load(&s)
+
+	// Assert
+	assert.Nil(t, err)
+	assert.Equal(t, "a,b", s.Pair)
+	assert.Equal(t, "", s.Code)
+	assert.Equal(t, "12", s.Value)
+}
+
+func TestLoadWithRegexFollowedByOption(t *testing.T) {
+	// Arrange
+	type S struct {
+		Value string `env:"TEST_VALUE,regex=^a$,optional"`
+	}
+
+	// Act
+	var s S
+	err := minienv.Load(&s)
+
+	// Assert
+	assert.Error(t, err)
+
+	tagParseErr := err.(minienv.LoadError)
+	assert.Equal(t, "Value", tagParseErr.Field)
+	assert.ErrorContains(t, tagParseErr, "regex is followed by the optional option, quote the pattern like regex='...' to use options after it")
+}
+
+func TestLoadWithUnclosedQuotedRegex(t *testing.T) {
+	// Arrange
+	type S struct {
+		Value string `env:"TEST_VALUE,regex='^a$,optional"`
+	}
+
+	// Act
+	var s S
+	err := minienv.Load(&s)
+
+	// Assert
+	assert.Error(t, err)
+	assert.ErrorContains(t, err, "regex pattern is missing its closing quote")
+}
+
+type Server struct {
+	Host string `env:"HOST"`
+	Port int    `env:"PORT,default=80"`
+}
+
+func TestLoadWithSliceOfStructs(t *testing.T) {
+	// Arrange
+	type S struct {
+		Servers []Server `env:"SERVERS"`
+	}
+
+	os.Setenv("SERVERS_0_HOST", "a.com")
+	defer os.Unsetenv("SERVERS_0_HOST")
+
+	os.Setenv("SERVERS_0_PORT", "8080")
+	defer os.Unsetenv("SERVERS_0_PORT")
+
+	os.Setenv("SERVERS_1_HOST", "b.com")
+	defer os.Unsetenv("SERVERS_1_HOST")
+
+	// after the gap at index 2
+	os.Setenv("SERVERS_3_HOST", "d.com")
+	defer os.Unsetenv("SERVERS_3_HOST")
+
+	// Act
+	var s S
+	err := minienv.Load(&s)
+
+	// Assert
+	assert.Nil(t, err)
+	assert.Equal(t, []Server{{Host: "a.com", Port: 8080}, {Host: "b.com", Port: 80}}, s.Servers)
+}
+
+func TestLoadWithSliceOfStructsAndPrefix(t *testing.T) {
+	// Arrange
+	type S struct {
+		Servers []Server `env:"SERVERS,optional"`
+	}
+
+	os.Setenv("APP_SERVERS_0_HOST", "a.com")
+	defer os.Unsetenv("APP_SERVERS_0_HOST")
+
+	// Act
+	var s S
+	err := minienv.Load(&s, minienv.WithPrefix("APP_"))
+
+	// Assert
+	assert.Nil(t, err)
+	assert.Equal(t, []Server{{Host: "a.com", Port: 80}}, s.Servers)
+}
+
+func TestLoadWithMissingSliceOfStructs(t *testing.T) {
+	// Arrange
+	type S struct {
+		Servers []Server `env:"SERVERS"`
+	}
+
+	// Act
+	var s S
+	err := minienv.Load(&s)
+
+	// Assert
+	assert.Error(t, err)
+	assert.ErrorContains(t, err, "required field has no elements under \"SERVERS_0_\"")
+}
@@ -0,0 +1,107 @@
+package minienv
+
+import (
+	"fmt"
+	"runtime"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Evaluates a minimal integer expression that can be used as a computed default.
+// Supported are integer literals, the variable NUMCPU and the operators *, + and -,
+// where * binds stronger than + and -. Whitespace is ignored.
+func evalExpr(expr string) (int, error) {
+	tokens, err := tokenizeExpr(expr)
+	if err != nil {
+		return 0, err
+	}
+
+	if len(tokens) == 0 {
+		return 0, fmt.Errorf("invalid expression \"%s\": expression is empty", expr)
+	}
+
+	// the result is a sum of products, so we multiply until we hit a + or -
+	sum := 0
+	sign := 1
+	product := 1
+	expectOperand := true
+
+	for _, token := range tokens {
+		if expectOperand {
+			v, err := evalOperand(token)
+			if err != nil {
+				return 0, fmt.Errorf("invalid expression \"%s\": %w", expr, err)
+			}
+
+			product *= v
+			expectOperand = false
+			continue
+		}
+
+		switch token {
+		case "*":
+		case "+", "-":
+			sum += sign * product
+			product = 1
+			sign = 1
+			if token == "-" {
+				sign = -1
+			}
+		default:
+			return 0, fmt.Errorf("invalid expression \"%s\": expected operator but got \"%s\"", expr, token)
+		}
+
+		expectOperand = true
+	}
+
+	if expectOperand {
+		return 0, fmt.Errorf("invalid expression \"%s\": missing operand at the end", expr)
+	}
+
+	return sum + sign*product, nil
+}
+
+// Splits an expression into operands and operators.
+func tokenizeExpr(expr string) ([]string, error) {
+	var tokens []string
+	var current strings.Builder
+
+	flush := func() {
+		if current.Len() > 0 {
+			tokens = append(tokens, current.String())
+			current.Reset()
+		}
+	}
+
+	for _, r := range expr {
+		switch {
+		case unicode.IsSpace(r):
+			flush()
+		case r == '*' || r == '+' || r == '-':
+			flush()
+			tokens = append(tokens, string(r))
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			current.WriteRune(r)
+		default:
+			return nil, fmt.Errorf("invalid expression \"%s\": unexpected character '%c'", expr, r)
+		}
+	}
+
+	flush()
+	return tokens, nil
+}
+
+// Evaluates a single operand which is either a literal or a known variable.
+func evalOperand(token string) (int, error) {
+	if token == "NUMCPU" {
+		return runtime.NumCPU(), nil
+	}
+
+	v, err := strconv.Atoi(token)
+	if err != nil {
+		return 0, fmt.Errorf("unknown operand \"%s\"", token)
+	}
+
+	return v, nil
+}
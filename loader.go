@@ -0,0 +1,39 @@
+package minienv
+
+import "context"
+
+// Loader applies a set of options once and can then load any number of structs with them.
+// This avoids reading the same env-files again for every struct that is loaded.
+type Loader struct {
+	config LoadConfig
+}
+
+// Create a new Loader with the provided options.
+// All options are applied immediately, so errors like a missing required env-file are returned here.
+func NewLoader(options ...Option) (*Loader, error) {
+	config, err := newLoadConfig(options...)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Loader{config: *config}, nil
+}
+
+// Load variables into the provided struct using the options of the Loader.
+// The obj must be a pointer to a struct.
+func (l *Loader) Load(obj interface{}) error {
+	return l.LoadContext(context.Background(), obj)
+}
+
+// LoadContext works like Load, but passes the context to any providers
+// that are consulted during loading.
+func (l *Loader) LoadContext(ctx context.Context, obj interface{}) error {
+	// every load works on its own copy, so loads don't share their audit or resolved values
+	config := l.config
+	config.ctx = ctx
+	config.audit = nil
+	config.resolved = make(map[string]string)
+	config.consistency = make(map[string][]consistentValue)
+
+	return loadStruct(obj, &config)
+}
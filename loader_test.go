@@ -0,0 +1,183 @@
+package minienv_test
+
+import (
+	"os"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/yannickalex07/minienv"
+)
+
+func TestLoader(t *testing.T) {
+	// Arrange
+	type Database struct {
+		Host string `env:"DB_HOST"`
+		Port int    `env:"DB_PORT"`
+	}
+
+	type Server struct {
+		Port int `env:"SERVER_PORT"`
+	}
+
+	os.Setenv("APP_DB_HOST", "localhost")
+	defer os.Unsetenv("APP_DB_HOST")
+
+	filename := "test.env"
+
+	CreateFile(t, filename, []string{
+		"APP_DB_PORT=5432",
+		"APP_SERVER_PORT=8080",
+	})
+	defer RemoveFile(t, filename)
+
+	loader, err := minienv.NewLoader(minienv.WithPrefix("APP_"), minienv.WithFile(true, filename))
+	assert.Nil(t, err)
+
+	// the file is read once when the loader is created
+	RemoveFile(t, filename)
+	CreateFile(t, filename, []string{})
+
+	// Act
+	var db Database
+	dbErr := loader.Load(&db)
+
+	var server Server
+	serverErr := loader.Load(&server)
+
+	// Assert
+	assert.Nil(t, dbErr)
+	assert.Equal(t, Database{Host: "localhost", Port: 5432}, db)
+
+	assert.Nil(t, serverErr)
+	assert.Equal(t, Server{Port: 8080}, server)
+}
+
+func TestLoaderConcurrentlyWithNormalizers(t *testing.T) {
+	// Arrange
+	type S struct {
+		Host string `env:"HOST"`
+	}
+
+	os.Setenv("HOST", " Example.com ")
+	defer os.Unsetenv("HOST")
+
+	trim := func(v reflect.Value) error {
+		v.SetString(strings.TrimSpace(v.String()))
+		return nil
+	}
+
+	lower := func(v reflect.Value) error {
+		v.SetString(strings.ToLower(v.String()))
+		return nil
+	}
+
+	loader, err := minienv.NewLoader(
+		minienv.WithFieldNormalizer("Host", trim),
+		minienv.WithFieldNormalizer("Host", trim),
+		minienv.WithFieldNormalizer("Host", trim),
+		minienv.WithFieldNormalizer("HOST", lower),
+	)
+	assert.Nil(t, err)
+
+	// Act
+	results := make([]S, 8)
+	errs := make([]error, len(results))
+
+	var wg sync.WaitGroup
+	for i := range results {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs[i] = loader.Load(&results[i])
+		}()
+	}
+
+	wg.Wait()
+
+	// Assert
+	for i := range results {
+		assert.Nil(t, errs[i])
+		assert.Equal(t, "example.com", results[i].Host)
+	}
+}
+
+func TestLoaderWithInvalidOption(t *testing.T) {
+	// Act
+	loader, err := minienv.NewLoader(minienv.WithFile(true, "missing.env"))
+
+	// Assert
+	assert.Error(t, err)
+	assert.Nil(t, loader)
+}
+
+func TestLoaderWithInvalidInput(t *testing.T) {
+	// Arrange
+	loader, err := minienv.NewLoader()
+	assert.Nil(t, err)
+
+	// Act
+	var s string
+	err = loader.Load(&s)
+
+	// Assert
+	assert.Equal(t, minienv.ErrInvalidInput, err)
+}
+
+func TestLoaderWithFrozenEnv(t *testing.T) {
+	// Arrange
+	type S struct {
+		Value string `env:"VALUE"`
+		Added string `env:"ADDED,optional"`
+	}
+
+	os.Setenv("VALUE", "original")
+	defer os.Unsetenv("VALUE")
+
+	loader, err := minienv.NewLoader(minienv.WithFrozenEnv())
+	assert.Nil(t, err)
+
+	// changes after the loader was created are not visible to it
+	os.Setenv("VALUE", "changed")
+	os.Setenv("ADDED", "added")
+	defer os.Unsetenv("ADDED")
+
+	// Act
+	var first S
+	firstErr := loader.Load(&first)
+
+	var second S
+	secondErr := loader.Load(&second)
+
+	// Assert
+	assert.Nil(t, firstErr)
+	assert.Equal(t, S{Value: "original"}, first)
+
+	assert.Nil(t, secondErr)
+	assert.Equal(t, first, second)
+}
+
+func TestLoaderWithoutFrozenEnv(t *testing.T) {
+	// Arrange
+	type S struct {
+		Value string `env:"VALUE"`
+	}
+
+	os.Setenv("VALUE", "original")
+	defer os.Unsetenv("VALUE")
+
+	loader, err := minienv.NewLoader()
+	assert.Nil(t, err)
+
+	os.Setenv("VALUE", "changed")
+
+	// Act
+	var s S
+	err = loader.Load(&s)
+
+	// Assert
+	assert.Nil(t, err)
+	assert.Equal(t, "changed", s.Value)
+}
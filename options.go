@@ -2,8 +2,15 @@ package minienv
 
 import (
 	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"log/slog"
 	"os"
+	"reflect"
 	"regexp"
+	"strings"
 )
 
 // Supply a map of values that will be used as fallback values if no
@@ -13,6 +20,24 @@ func WithFallbackValues(values map[string]string) Option {
 	return func(c *LoadConfig) error {
 		for k, v := range values {
 			c.Values[k] = v
+			c.origins[k] = SourceFallback
+		}
+
+		return nil
+	}
+}
+
+// Supply a map of values that take precedence over the environment and every other source,
+// for example to get deterministic values in tests without modifying the environment.
+// The keys are case-sensitive.
+func WithOverrides(values map[string]string) Option {
+	return func(c *LoadConfig) error {
+		if c.Overrides == nil {
+			c.Overrides = make(map[string]string)
+		}
+
+		for k, v := range values {
+			c.Overrides[k] = v
 		}
 
 		return nil
@@ -31,21 +56,278 @@ func WithPrefix(prefix string) Option {
 // uses as fallback values in case no matching env variable was found.
 func WithFile(required bool, files ...string) Option {
 	return func(c *LoadConfig) error {
-		values, err := readEnvFiles(required, files...)
+		values, err := readEnvFiles(openFile, required, c, files...)
 		if err != nil {
 			return err
 		}
 
 		for k, v := range values {
 			c.Values[k] = v
+			c.origins[k] = SourceFile
+		}
+		c.fileLoaded = true
+
+		return nil
+	}
+}
+
+// Supply a list of files in a filesystem like an embed.FS to load environment variables from,
+// which works just like WithFile otherwise.
+func WithFileFS(fsys fs.FS, required bool, files ...string) Option {
+	return func(c *LoadConfig) error {
+		open := func(name string) (io.ReadCloser, error) {
+			return fsys.Open(name)
+		}
+
+		values, err := readEnvFiles(open, required, c, files...)
+		if err != nil {
+			return err
+		}
+
+		for k, v := range values {
+			c.Values[k] = v
+			c.origins[k] = SourceFile
+		}
+		c.fileLoaded = true
+
+		return nil
+	}
+}
+
+// Supply a reader to load environment variables from, for example an embedded file or an HTTP response body.
+// The content is parsed like an env-file and its values are used as fallback values in case no matching env variable was found.
+func WithReader(r io.Reader) Option {
+	return func(c *LoadConfig) error {
+		values, err := parseEnv(r, c, nil)
+		if err != nil {
+			return err
+		}
+
+		for k, v := range values {
+			c.Values[k] = v
+			c.origins[k] = SourceFile
+		}
+		c.fileLoaded = true
+
+		return nil
+	}
+}
+
+// Supply the key of a variable that contains the whole config as a JSON object.
+// The object is decoded into the struct first, afterwards every field that has a value in the
+// environment, a provider, an env-file or the fallback values is overridden with it.
+// Fields that were set by the JSON object are neither required nor replaced by their default.
+func WithJSONBlob(key string) Option {
+	return func(c *LoadConfig) error {
+		c.blobKey = key
+		return nil
+	}
+}
+
+// Supply the bits of named flags for fields with the `bitflags` option.
+// The names in the value of such a field are OR-ed together, so "read|write" with
+// read=1 and write=2 results in 3.
+func WithBitFlags(flags map[string]int) Option {
+	return func(c *LoadConfig) error {
+		if c.bitFlags == nil {
+			c.bitFlags = make(map[string]int)
+		}
+
+		for k, v := range flags {
+			c.bitFlags[k] = v
+		}
+
+		return nil
+	}
+}
+
+// Lowercase every key, including the prefix, before it is looked up in the environment.
+// This allows uppercase tags to be used on platforms that use lowercase variable names.
+// Overrides, providers, env-files and fallback values are still looked up with the key as it is.
+func WithLowercaseKeys() Option {
+	return func(c *LoadConfig) error {
+		c.lowercaseKeys = true
+		return nil
+	}
+}
+
+// Supply a different separator for the options of a tag instead of ",".
+// This allows commas to be used in option values, for example `env:"HOSTS;optional;split=,"`.
+func WithTagSeparator(separator rune) Option {
+	return func(c *LoadConfig) error {
+		c.tagSeparator = separator
+		return nil
+	}
+}
+
+// Select a profile whose tag variants are preferred over the regular `env` tag.
+// With WithProfile("prod"), a field tagged `env:"KEY" env_prod:"PROD_KEY,default=x"` uses
+// the `env_prod` tag, fields without such a variant keep using their `env` tag.
+func WithProfile(profile string) Option {
+	return func(c *LoadConfig) error {
+		c.profile = profile
+		return nil
+	}
+}
+
+// Make every field optional unless it has the `required` option, instead of the other way around.
+// Defaults are still used for fields that have no value.
+func WithOptionalByDefault() Option {
+	return func(c *LoadConfig) error {
+		c.optionalByDefault = true
+		return nil
+	}
+}
+
+// Supply a logger that is used to report warnings during loading.
+// By default slog.Default() is used.
+func WithLogger(logger *slog.Logger) Option {
+	return func(c *LoadConfig) error {
+		if logger == nil {
+			return errors.New("logger must not be nil")
+		}
+
+		c.logger = logger
+		return nil
+	}
+}
+
+// Log a warning if an environment variable overrides a value from an env-file
+// or the fallback values that is different from it.
+// Values are not included in the warning, only the key and the overridden source.
+func WithWarnOnConflict() Option {
+	return func(c *LoadConfig) error {
+		c.warnOnConflict = true
+		return nil
+	}
+}
+
+// Supply a function that is run after a specific field was set.
+// The field is identified either by its name in the struct or by the key in its `env` tag.
+// The function receives the settable value of the field and can modify it in place,
+// returning an error aborts the load.
+func WithFieldNormalizer(field string, normalize func(reflect.Value) error) Option {
+	return func(c *LoadConfig) error {
+		c.normalizers[field] = append(c.normalizers[field], normalize)
+		return nil
+	}
+}
+
+// Supply a function that validates the struct after all of its fields were loaded successfully,
+// for example to check rules that span multiple fields. It receives the pointer that was passed to Load
+// and its error is returned by Load. Multiple validators are run in the order they were supplied.
+func WithValidator(validate func(any) error) Option {
+	return func(c *LoadConfig) error {
+		if validate == nil {
+			return errors.New("validator must not be nil")
 		}
 
+		c.validators = append(c.validators, validate)
 		return nil
 	}
 }
 
-// Reads a list of env-files and sets them in the load config
-func readEnvFiles(shouldRaiseError bool, files ...string) (map[string]string, error) {
+// Supply a codec that decodes the raw bytes of a value into every field of the given type,
+// for example a binary format like gob. The codec receives the settable value of the field.
+// Codecs are used after the chain option, so binary values that are base64 encoded in the
+// environment can be decoded with `chain=base64` first.
+func WithCodec(t reflect.Type, decode func([]byte, reflect.Value) error) Option {
+	return func(c *LoadConfig) error {
+		if c.codecs == nil {
+			c.codecs = make(map[reflect.Type]func([]byte, reflect.Value) error)
+		}
+
+		c.codecs[t] = decode
+		return nil
+	}
+}
+
+// Supply the reader that fields with the `stdin` option read from instead of os.Stdin,
+// as well as the maximum number of bytes that are read. A limit of 0 keeps the default of 1 MiB.
+func WithStdin(r io.Reader, limit int64) Option {
+	return func(c *LoadConfig) error {
+		if limit < 0 {
+			return errors.New("stdin limit must not be negative")
+		}
+
+		c.stdin = r
+		if limit > 0 {
+			c.stdinLimit = limit
+		}
+
+		return nil
+	}
+}
+
+// Supply the function the environment is read from instead of os.LookupEnv,
+// which allows loading without modifying the environment of the process, for example in tests.
+// References in env-files are resolved with it as well if the option precedes WithFile.
+// It can't be combined with WithFrozenEnv, whose snapshot is taken from the process environment.
+func WithEnvSource(source func(string) (string, bool)) Option {
+	return func(c *LoadConfig) error {
+		if source == nil {
+			return errors.New("env source must not be nil")
+		}
+
+		c.envSource = source
+		return nil
+	}
+}
+
+// Capture the environment once when the option is applied and read from that snapshot instead of the live environment.
+// Combined with NewLoader, every struct loaded through the Loader sees the same environment,
+// even if the process environment changes between loads.
+// The snapshot is always taken from the process environment, so the option can't be combined with WithEnvSource.
+func WithFrozenEnv() Option {
+	return func(c *LoadConfig) error {
+		c.env = make(map[string]string)
+		for _, entry := range os.Environ() {
+			k, v, _ := strings.Cut(entry, "=")
+			c.env[k] = v
+		}
+
+		return nil
+	}
+}
+
+// Collect keys that occur multiple times in env-files into a single value
+// joined by "|" instead of only keeping the last occurrence.
+// This allows slice fields to be populated from repeated keys.
+//
+// This option has to be passed before any WithFile, WithFileFS or WithReader option, otherwise an error is returned.
+func WithAccumulateDuplicates() Option {
+	return func(c *LoadConfig) error {
+		if c.fileLoaded {
+			return errors.New("WithAccumulateDuplicates must be passed before WithFile, WithFileFS and WithReader")
+		}
+
+		c.accumulate = true
+		return nil
+	}
+}
+
+// Return an error for references like ${HOST} in env-files that can't be resolved,
+// instead of keeping them as they are. The error is also returned for files that aren't required.
+//
+// This option has to be passed before any WithFile, WithFileFS or WithReader option, otherwise an error is returned.
+func WithStrictExpansion() Option {
+	return func(c *LoadConfig) error {
+		if c.fileLoaded {
+			return errors.New("WithStrictExpansion must be passed before WithFile, WithFileFS and WithReader")
+		}
+
+		c.strictExpansion = true
+		return nil
+	}
+}
+
+// Opens a file on disk for readEnvFiles
+func openFile(name string) (io.ReadCloser, error) {
+	return os.Open(name)
+}
+
+// Reads a list of env-files opened with the given function and sets them in the load config
+func readEnvFiles(open func(string) (io.ReadCloser, error), shouldRaiseError bool, c *LoadConfig, files ...string) (map[string]string, error) {
 	values := make(map[string]string)
 
 	if len(files) == 0 || files == nil {
@@ -53,9 +335,10 @@ func readEnvFiles(shouldRaiseError bool, files ...string) (map[string]string, er
 	}
 
 	for _, file := range files {
-		envs, err := parseEnvFile(file)
+		envs, err := parseEnvFile(open, file, c, values)
 		if err != nil {
-			if shouldRaiseError {
+			var unresolved unresolvedReferenceError
+			if shouldRaiseError || errors.As(err, &unresolved) {
 				return nil, err
 			}
 
@@ -63,49 +346,163 @@ func readEnvFiles(shouldRaiseError bool, files ...string) (map[string]string, er
 		}
 
 		for k, v := range envs {
-			values[k] = v
+			addValue(values, k, v, c.accumulate)
 		}
 	}
 
 	return values, nil
 }
 
-func parseEnvFile(path string) (map[string]string, error) {
+func parseEnvFile(open func(string) (io.ReadCloser, error), path string, c *LoadConfig, known map[string]string) (map[string]string, error) {
 	// open file
-	file, err := os.Open(path)
+	file, err := open(path)
 	if err != nil {
 		return nil, err
 	}
 	defer file.Close()
 
+	return parseEnv(file, c, known)
+}
+
+// Parses the content of an env-file from any reader.
+// References in its values are resolved against the values of the file itself, the known values of
+// previous files, the values of the config and the environment in that order.
+func parseEnv(r io.Reader, c *LoadConfig, known map[string]string) (map[string]string, error) {
 	overrides := map[string]string{}
 
+	lookup := func(key string) (string, bool) {
+		for _, values := range []map[string]string{overrides, known, c.Values} {
+			if v, found := values[key]; found {
+				return v, true
+			}
+		}
+
+		return lookupEnv(key, c)
+	}
+
 	// scan file
-	scanner := bufio.NewScanner(file)
+	scanner := bufio.NewScanner(r)
 	scanner.Split(bufio.ScanLines)
 
 	// compile regex
-	r, err := regexp.Compile(`^(?P<key>\w+)=["']?(?P<value>[^'"]*)['"]?.*$`)
+	re, err := regexp.Compile(`^(?P<key>\w+)=["']?(?P<value>[^'"]*)['"]?.*$`)
 	if err != nil {
 		return nil, err
 	}
 
+	// lines ending in a backslash are joined with the following line
+	var pending string
 	for scanner.Scan() {
-		line := scanner.Text()
+		line := pending + scanner.Text()
+		pending = ""
 
-		// skip empty lines
-		if len(line) == 0 {
+		if continuesLine(line) {
+			pending = line[:len(line)-1]
 			continue
 		}
 
-		// check if line is a valid env line
-		matches := r.FindStringSubmatch(line)
-		if len(matches) == 0 || matches == nil {
-			continue
+		err = parseEnvLine(re, line, overrides, c, lookup)
+		if err != nil {
+			return nil, err
 		}
+	}
+
+	// a backslash on the final line has nothing to continue with, so it is just dropped
+	if pending != "" {
+		err = parseEnvLine(re, pending, overrides, c, lookup)
+		if err != nil {
+			return nil, err
+		}
+	}
 
-		overrides[matches[r.SubexpIndex("key")]] = matches[r.SubexpIndex("value")]
+	if err := scanner.Err(); err != nil {
+		return nil, err
 	}
 
 	return overrides, nil
 }
+
+// Parses a single (possibly joined) line of an env file and adds its value
+func parseEnvLine(r *regexp.Regexp, line string, values map[string]string, c *LoadConfig, lookup func(string) (string, bool)) error {
+	// skip empty lines
+	if len(line) == 0 {
+		return nil
+	}
+
+	// check if line is a valid env line
+	matches := r.FindStringSubmatch(line)
+	if len(matches) == 0 || matches == nil {
+		return nil
+	}
+
+	val, err := expandReferences(matches[r.SubexpIndex("value")], lookup, c.strictExpansion)
+	if err != nil {
+		return err
+	}
+
+	addValue(values, matches[r.SubexpIndex("key")], val, c.accumulate)
+	return nil
+}
+
+// Matches references to other variables like ${HOST} in the values of env-files
+var referenceRegex = regexp.MustCompile(`\$\{(\w+)\}`)
+
+// The error for a reference in an env-file that can't be resolved
+type unresolvedReferenceError struct {
+	reference string
+}
+
+func (e unresolvedReferenceError) Error() string {
+	return fmt.Sprintf("unresolved reference %s", e.reference)
+}
+
+// Replaces references like ${HOST} with the value of the variable. Unresolved references are kept
+// as they are, unless strict is set. A plain $HOST is never expanded, so values like passwords keep their "$".
+func expandReferences(val string, lookup func(string) (string, bool), strict bool) (string, error) {
+	var err error
+	expanded := referenceRegex.ReplaceAllStringFunc(val, func(ref string) string {
+		if v, found := lookup(ref[2 : len(ref)-1]); found {
+			return v
+		}
+
+		if strict && err == nil {
+			err = unresolvedReferenceError{reference: ref}
+		}
+
+		return ref
+	})
+
+	return expanded, err
+}
+
+// Checks if the line ends in a backslash that isn't itself escaped by another backslash
+func continuesLine(line string) bool {
+	trailing := len(line) - len(strings.TrimRight(line, "\\"))
+	return trailing%2 == 1
+}
+
+// Reads the whole stdin of the config as a value, a single trailing line break is removed.
+// Since the reader is consumed, only the first field reading from it receives the content.
+func readStdin(c *LoadConfig) (string, error) {
+	data, err := io.ReadAll(io.LimitReader(c.stdin, c.stdinLimit+1))
+	if err != nil {
+		return "", fmt.Errorf("failed to read from stdin: %w", err)
+	}
+
+	if int64(len(data)) > c.stdinLimit {
+		return "", fmt.Errorf("stdin exceeds the limit of %d bytes", c.stdinLimit)
+	}
+
+	val := strings.TrimSuffix(string(data), "\n")
+	return strings.TrimSuffix(val, "\r"), nil
+}
+
+// Sets a value in the map, either overwriting or appending to an existing value
+func addValue(values map[string]string, key string, val string, accumulate bool) {
+	if existing, ok := values[key]; ok && accumulate {
+		values[key] = existing + defaultDelimiter + val
+		return
+	}
+
+	values[key] = val
+}
@@ -1,8 +1,19 @@
 package minienv_test
 
 import (
+	"bytes"
+	"encoding/base64"
+	"encoding/gob"
+	"errors"
+	"io"
+	"io/fs"
+	"log/slog"
 	"os"
+	"reflect"
+	"strings"
 	"testing"
+	"testing/fstest"
+	"testing/iotest"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/yannickalex07/minienv"
@@ -89,6 +100,41 @@ func TestWithFileAndQuoted(t *testing.T) {
 	assert.Equal(t, "single", s.Single)
 }
 
+func TestWithFileAndLineContinuation(t *testing.T) {
+	// Arrange
+	type S struct {
+		Continued string `env:"CONTINUED"`
+		Quoted    string `env:"QUOTED"`
+		Escaped   string `env:"ESCAPED"`
+		Last      string `env:"LAST"`
+	}
+
+	// create env file
+	filename := "test.env"
+
+	CreateFile(t, filename, []string{
+		"CONTINUED=part1 \\",
+		"part2 \\",
+		"part3",
+		"QUOTED=\"a \\",
+		"b\"",
+		"ESCAPED=path\\\\",
+		"LAST=final\\",
+	})
+	defer RemoveFile(t, filename)
+
+	// Act
+	var s S
+	err := minienv.Load(&s, minienv.WithFile(false, filename))
+
+	// Assert
+	assert.Nil(t, err)
+	assert.Equal(t, "part1 part2 part3", s.Continued)
+	assert.Equal(t, "a b", s.Quoted)
+	assert.Equal(t, "path\\\\", s.Escaped)
+	assert.Equal(t, "final", s.Last)
+}
+
 func TestWithFileAndMissingOptionalFile(t *testing.T) {
 	// Arrange
 	type S struct {
@@ -223,6 +269,209 @@ func TestWithEmptyLines(t *testing.T) {
 	assert.Equal(t, "val", s.Value)
 }
 
+func TestWithFileFS(t *testing.T) {
+	// Arrange
+	type S struct {
+		Host string `env:"HOST"`
+		Port int    `env:"PORT"`
+	}
+
+	fsys := fstest.MapFS{
+		"config/base.env":  {Data: []byte("HOST=localhost\nPORT=80\n")},
+		"config/extra.env": {Data: []byte("# override\nPORT='8080'\n")},
+	}
+
+	// Act
+	var s S
+	err := minienv.Load(&s, minienv.WithFileFS(fsys, true, "config/base.env", "config/extra.env"))
+
+	// Assert
+	assert.Nil(t, err)
+	assert.Equal(t, "localhost", s.Host)
+	assert.Equal(t, 8080, s.Port)
+}
+
+func TestWithFileFSAndMissingOptionalFile(t *testing.T) {
+	// Arrange
+	type S struct {
+		Value string `env:"VAL,optional"`
+	}
+
+	// Act
+	var s S
+	err := minienv.Load(&s, minienv.WithFileFS(fstest.MapFS{}, false, "missing.env"))
+
+	// Assert
+	assert.Nil(t, err)
+	assert.Equal(t, "", s.Value)
+}
+
+func TestWithFileFSAndMissingRequiredFile(t *testing.T) {
+	// Arrange
+	type S struct {
+		Value string `env:"VAL,optional"`
+	}
+
+	// Act
+	var s S
+	err := minienv.Load(&s, minienv.WithFileFS(fstest.MapFS{}, true, "missing.env"))
+
+	// Assert
+	assert.ErrorIs(t, err, fs.ErrNotExist)
+}
+
+func TestWithReader(t *testing.T) {
+	// Arrange
+	type S struct {
+		Double string `env:"DOUBLE"`
+		Single string `env:"SINGLE"`
+		Plain  string `env:"PLAIN"`
+	}
+
+	r := strings.NewReader("DOUBLE=\"double\"\nSINGLE='single'\n\n# comment\nPLAIN=plain\n")
+
+	// Act
+	var s S
+	err := minienv.Load(&s, minienv.WithReader(r))
+
+	// Assert
+	assert.Nil(t, err)
+	assert.Equal(t, "double", s.Double)
+	assert.Equal(t, "single", s.Single)
+	assert.Equal(t, "plain", s.Plain)
+}
+
+func TestWithReaderAndOverride(t *testing.T) {
+	// Arrange
+	type S struct {
+		Value string `env:"VAL"`
+	}
+
+	os.Setenv("VAL", "env")
+	defer os.Unsetenv("VAL")
+
+	// Act
+	var s S
+	err := minienv.Load(&s, minienv.WithReader(strings.NewReader("VAL=reader")))
+
+	// Assert
+	assert.Nil(t, err)
+	assert.Equal(t, "env", s.Value)
+}
+
+func TestWithReaderAndError(t *testing.T) {
+	// Arrange
+	type S struct {
+		Value string `env:"VAL"`
+	}
+
+	r := io.MultiReader(strings.NewReader("VAL=val\n"), iotest.ErrReader(errors.New("connection reset")))
+
+	// Act
+	var s S
+	err := minienv.Load(&s, minienv.WithReader(r))
+
+	// Assert
+	assert.Error(t, err)
+	assert.ErrorContains(t, err, "connection reset")
+}
+
+func TestWithFileAndReferences(t *testing.T) {
+	// Arrange
+	type S struct {
+		URL      string `env:"URL"`
+		Home     string `env:"HOME_DIR"`
+		Missing  string `env:"MISSING"`
+		Password string `env:"PASSWORD"`
+	}
+
+	os.Setenv("TEST_USER_HOME", "/home/test")
+	defer os.Unsetenv("TEST_USER_HOME")
+
+	filename := "test.env"
+
+	CreateFile(t, filename, []string{
+		"HOST=localhost",
+		"URL=http://${HOST}:8080",
+		"HOME_DIR=${TEST_USER_HOME}/app",
+		"MISSING=${NOT_DEFINED}",
+		"PASSWORD=pa$$word",
+	})
+	defer RemoveFile(t, filename)
+
+	// Act
+	var s S
+	err := minienv.Load(&s, minienv.WithFile(false, filename))
+
+	// Assert
+	assert.Nil(t, err)
+	assert.Equal(t, "http://localhost:8080", s.URL)
+	assert.Equal(t, "/home/test/app", s.Home)
+	assert.Equal(t, "${NOT_DEFINED}", s.Missing)
+	assert.Equal(t, "pa$$word", s.Password)
+}
+
+func TestWithFileAndReferencesAcrossFiles(t *testing.T) {
+	// Arrange
+	type S struct {
+		URL string `env:"URL"`
+	}
+
+	CreateFile(t, "base.env", []string{"HOST=db.internal"})
+	defer RemoveFile(t, "base.env")
+
+	CreateFile(t, "extra.env", []string{"URL=postgres://${HOST}/app"})
+	defer RemoveFile(t, "extra.env")
+
+	// Act
+	var s S
+	err := minienv.Load(&s, minienv.WithFile(true, "base.env", "extra.env"))
+
+	// Assert
+	assert.Nil(t, err)
+	assert.Equal(t, "postgres://db.internal/app", s.URL)
+}
+
+func TestWithStrictExpansion(t *testing.T) {
+	// Arrange
+	type S struct {
+		Value string `env:"VAL"`
+	}
+
+	filename := "test.env"
+
+	CreateFile(t, filename, []string{"VAL=${NOT_DEFINED}"})
+	defer RemoveFile(t, filename)
+
+	// Act
+	var s S
+	err := minienv.Load(&s, minienv.WithStrictExpansion(), minienv.WithFile(false, filename))
+
+	// Assert
+	assert.Error(t, err)
+	assert.ErrorContains(t, err, "unresolved reference ${NOT_DEFINED}")
+}
+
+func TestWithStrictExpansionAfterFile(t *testing.T) {
+	// Arrange
+	type S struct {
+		Value string `env:"VAL"`
+	}
+
+	filename := "test.env"
+
+	CreateFile(t, filename, []string{"VAL=${NOT_DEFINED}"})
+	defer RemoveFile(t, filename)
+
+	// Act
+	var s S
+	err := minienv.Load(&s, minienv.WithFile(false, filename), minienv.WithStrictExpansion())
+
+	// Assert
+	assert.Error(t, err)
+	assert.ErrorContains(t, err, "WithStrictExpansion must be passed before WithFile, WithFileFS and WithReader")
+}
+
 func TestWithPrefix(t *testing.T) {
 	// Arrange
 	type S struct {
@@ -240,3 +489,1015 @@ func TestWithPrefix(t *testing.T) {
 	assert.Nil(t, err)
 	assert.Equal(t, "test-value", s.Value)
 }
+
+func TestWithAccumulateDuplicates(t *testing.T) {
+	// Arrange
+	type S struct {
+		Tags []string `env:"TAG"`
+		Last string   `env:"LAST"`
+	}
+
+	filename := "test.env"
+
+	CreateFile(t, filename, []string{
+		"TAG=a",
+		"TAG=b",
+		"LAST=one",
+	})
+	defer RemoveFile(t, filename)
+
+	// Act
+	var s S
+	err := minienv.Load(&s, minienv.WithAccumulateDuplicates(), minienv.WithFile(true, filename))
+
+	// Assert
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"a", "b"}, s.Tags)
+	assert.Equal(t, "one", s.Last)
+}
+
+func TestWithAccumulateDuplicatesAcrossFiles(t *testing.T) {
+	// Arrange
+	type S struct {
+		Tags []string `env:"TAG"`
+	}
+
+	filename1 := "one.env"
+	filename2 := "two.env"
+
+	CreateFile(t, filename1, []string{
+		"TAG=a",
+	})
+	defer RemoveFile(t, filename1)
+
+	CreateFile(t, filename2, []string{
+		"TAG=b",
+	})
+	defer RemoveFile(t, filename2)
+
+	// Act
+	var s S
+	err := minienv.Load(&s, minienv.WithAccumulateDuplicates(), minienv.WithFile(true, filename1, filename2))
+
+	// Assert
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"a", "b"}, s.Tags)
+}
+
+func TestWithAccumulateDuplicatesAfterReader(t *testing.T) {
+	// Arrange
+	type S struct {
+		Tags []string `env:"TAG"`
+	}
+
+	// Act
+	var s S
+	err := minienv.Load(&s, minienv.WithReader(strings.NewReader("TAG=a\nTAG=b")), minienv.WithAccumulateDuplicates())
+
+	// Assert
+	assert.Error(t, err)
+	assert.ErrorContains(t, err, "WithAccumulateDuplicates must be passed before WithFile, WithFileFS and WithReader")
+}
+
+func TestWithoutAccumulateDuplicates(t *testing.T) {
+	// Arrange
+	type S struct {
+		Tags []string `env:"TAG"`
+	}
+
+	filename := "test.env"
+
+	CreateFile(t, filename, []string{
+		"TAG=a",
+		"TAG=b",
+	})
+	defer RemoveFile(t, filename)
+
+	// Act
+	var s S
+	err := minienv.Load(&s, minienv.WithFile(true, filename))
+
+	// Assert
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"b"}, s.Tags)
+}
+
+func TestWithFieldNormalizer(t *testing.T) {
+	// Arrange
+	type S struct {
+		ByName string `env:"BY_NAME"`
+		ByKey  string `env:"BY_KEY"`
+		Other  string `env:"OTHER"`
+	}
+
+	os.Setenv("BY_NAME", "https://example.com/")
+	defer os.Unsetenv("BY_NAME")
+
+	os.Setenv("BY_KEY", "https://example.com/")
+	defer os.Unsetenv("BY_KEY")
+
+	os.Setenv("OTHER", "https://example.com/")
+	defer os.Unsetenv("OTHER")
+
+	trimSlash := func(v reflect.Value) error {
+		v.SetString(strings.TrimSuffix(v.String(), "/"))
+		return nil
+	}
+
+	// Act
+	var s S
+	err := minienv.Load(&s, minienv.WithFieldNormalizer("ByName", trimSlash), minienv.WithFieldNormalizer("BY_KEY", trimSlash))
+
+	// Assert
+	assert.Nil(t, err)
+	assert.Equal(t, "https://example.com", s.ByName)
+	assert.Equal(t, "https://example.com", s.ByKey)
+	assert.Equal(t, "https://example.com/", s.Other)
+}
+
+func TestWithFieldNormalizerAndError(t *testing.T) {
+	// Arrange
+	type S struct {
+		Value int `env:"VALUE"`
+	}
+
+	os.Setenv("VALUE", "-1")
+	defer os.Unsetenv("VALUE")
+
+	positive := func(v reflect.Value) error {
+		if v.Int() < 0 {
+			return errors.New("must be positive")
+		}
+
+		return nil
+	}
+
+	// Act
+	var s S
+	err := minienv.Load(&s, minienv.WithFieldNormalizer("Value", positive))
+
+	// Assert
+	assert.Error(t, err)
+
+	loadErr := err.(minienv.LoadError)
+	assert.Equal(t, "Value", loadErr.Field)
+	assert.ErrorContains(t, loadErr, "must be positive")
+}
+
+func TestWithWarnOnConflict(t *testing.T) {
+	// Arrange
+	type S struct {
+		Conflict string `env:"CONFLICT"`
+		Same     string `env:"SAME"`
+	}
+
+	os.Setenv("CONFLICT", "from-env")
+	defer os.Unsetenv("CONFLICT")
+
+	os.Setenv("SAME", "same")
+	defer os.Unsetenv("SAME")
+
+	filename := "test.env"
+
+	CreateFile(t, filename, []string{
+		"CONFLICT=from-file",
+		"SAME=same",
+	})
+	defer RemoveFile(t, filename)
+
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&buf, nil))
+
+	// Act
+	var s S
+	err := minienv.Load(&s, minienv.WithFile(true, filename), minienv.WithLogger(logger), minienv.WithWarnOnConflict())
+
+	// Assert
+	assert.Nil(t, err)
+	assert.Equal(t, "from-env", s.Conflict)
+	assert.Contains(t, buf.String(), "level=WARN")
+	assert.Contains(t, buf.String(), "key=CONFLICT overridden=file")
+	assert.NotContains(t, buf.String(), "key=SAME")
+	assert.NotContains(t, buf.String(), "from-file")
+}
+
+func TestWithoutWarnOnConflict(t *testing.T) {
+	// Arrange
+	type S struct {
+		Conflict string `env:"CONFLICT"`
+	}
+
+	os.Setenv("CONFLICT", "from-env")
+	defer os.Unsetenv("CONFLICT")
+
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&buf, nil))
+
+	// Act
+	var s S
+	err := minienv.Load(&s, minienv.WithFallbackValues(map[string]string{"CONFLICT": "from-fallback"}), minienv.WithLogger(logger))
+
+	// Assert
+	assert.Nil(t, err)
+	assert.Equal(t, "from-env", s.Conflict)
+	assert.Empty(t, buf.String())
+}
+
+func TestWithJSONBlob(t *testing.T) {
+	// Arrange
+	type S struct {
+		Host string `env:"HOST" json:"host"`
+		Port int    `env:"PORT" json:"port"`
+		DB   struct {
+			Name string `env:"DB_NAME" json:"name"`
+		} `json:"db"`
+	}
+
+	os.Setenv("APP_CONFIG", `{"host": "blob-host", "port": 8080, "db": {"name": "blob-db"}}`)
+	defer os.Unsetenv("APP_CONFIG")
+
+	// Act
+	var s S
+	err := minienv.Load(&s, minienv.WithJSONBlob("APP_CONFIG"))
+
+	// Assert
+	assert.Nil(t, err)
+	assert.Equal(t, "blob-host", s.Host)
+	assert.Equal(t, 8080, s.Port)
+	assert.Equal(t, "blob-db", s.DB.Name)
+}
+
+func TestWithJSONBlobAndOverrides(t *testing.T) {
+	// Arrange
+	type S struct {
+		Host    string `env:"HOST" json:"host"`
+		Port    int    `env:"PORT,default=80" json:"port"`
+		Timeout int    `env:"TIMEOUT,default=30" json:"timeout"`
+	}
+
+	os.Setenv("APP_CONFIG", `{"host": "blob-host", "port": 8080}`)
+	defer os.Unsetenv("APP_CONFIG")
+
+	os.Setenv("HOST", "env-host")
+	defer os.Unsetenv("HOST")
+
+	// Act
+	var s S
+	err := minienv.Load(&s, minienv.WithJSONBlob("APP_CONFIG"))
+
+	// Assert
+	assert.Nil(t, err)
+	assert.Equal(t, "env-host", s.Host)
+	assert.Equal(t, 8080, s.Port) // the blob wins over the default
+	assert.Equal(t, 30, s.Timeout)
+}
+
+func TestWithJSONBlobAndZeroValues(t *testing.T) {
+	// Arrange
+	type S struct {
+		Debug bool `env:"DEBUG,default=true"`
+		N     int  `env:"N"`
+	}
+
+	os.Setenv("APP_CONFIG", `{"Debug": false, "N": 0}`)
+	defer os.Unsetenv("APP_CONFIG")
+
+	// Act
+	var s S
+	entries, err := minienv.LoadWithAudit(&s, minienv.WithJSONBlob("APP_CONFIG"))
+
+	// Assert
+	assert.Nil(t, err)
+	assert.False(t, s.Debug)
+	assert.Equal(t, 0, s.N)
+
+	assert.Equal(t, minienv.SourceJSONBlob, entries[0].Source)
+	assert.Equal(t, minienv.SourceJSONBlob, entries[1].Source)
+}
+
+func TestWithJSONBlobAndReloadPrefix(t *testing.T) {
+	// Arrange
+	type S struct {
+		Host      string `env:"HOST" json:"host"`
+		CacheSize int    `env:"CACHE_SIZE" json:"cache_size"`
+		CacheTTL  string `env:"CACHE_TTL" json:"cache_ttl"`
+	}
+
+	os.Setenv("APP_CONFIG", `{"host": "blob-host", "cache_size": 10, "cache_ttl": "1m"}`)
+	defer os.Unsetenv("APP_CONFIG")
+
+	os.Setenv("CACHE_SIZE", "20")
+	defer os.Unsetenv("CACHE_SIZE")
+
+	// Act
+	s := S{Host: "changed", CacheSize: 1, CacheTTL: "1s"}
+	err := minienv.ReloadPrefix(&s, "CACHE_", minienv.WithJSONBlob("APP_CONFIG"))
+
+	// Assert
+	assert.Nil(t, err)
+	assert.Equal(t, "changed", s.Host)
+	assert.Equal(t, 20, s.CacheSize)
+	assert.Equal(t, "1m", s.CacheTTL)
+}
+
+func TestWithJSONBlobAndMissingVariable(t *testing.T) {
+	// Arrange
+	type S struct {
+		Host string `env:"HOST" json:"host"`
+	}
+
+	os.Setenv("HOST", "env-host")
+	defer os.Unsetenv("HOST")
+
+	// Act
+	var s S
+	err := minienv.Load(&s, minienv.WithJSONBlob("APP_CONFIG"))
+
+	// Assert
+	assert.Nil(t, err)
+	assert.Equal(t, "env-host", s.Host)
+}
+
+func TestWithJSONBlobAndInvalidJSON(t *testing.T) {
+	// Arrange
+	type S struct {
+		Host string `env:"HOST" json:"host"`
+	}
+
+	os.Setenv("APP_CONFIG", `{"host": `)
+	defer os.Unsetenv("APP_CONFIG")
+
+	// Act
+	var s S
+	err := minienv.Load(&s, minienv.WithJSONBlob("APP_CONFIG"))
+
+	// Assert
+	assert.Error(t, err)
+
+	blobErr := err.(minienv.LoadError)
+	assert.Equal(t, "APP_CONFIG", blobErr.Field)
+	assert.ErrorContains(t, blobErr, "failed to decode JSON blob")
+}
+
+func TestWithBitFlags(t *testing.T) {
+	// Arrange
+	type S struct {
+		Perms int `env:"PERMS,bitflags"`
+		None  int `env:"NONE,bitflags,optional"`
+	}
+
+	os.Setenv("PERMS", "read|delete")
+	defer os.Unsetenv("PERMS")
+
+	flags := map[string]int{
+		"read":   1 << 0,
+		"write":  1 << 1,
+		"delete": 1 << 2,
+	}
+
+	// Act
+	var s S
+	err := minienv.Load(&s, minienv.WithBitFlags(flags))
+
+	// Assert
+	assert.Nil(t, err)
+	assert.Equal(t, 5, s.Perms)
+	assert.Equal(t, 0, s.None)
+}
+
+func TestWithBitFlagsAndUnknownFlag(t *testing.T) {
+	// Arrange
+	type S struct {
+		Perms int `env:"PERMS,bitflags"`
+	}
+
+	os.Setenv("PERMS", "read|execute")
+	defer os.Unsetenv("PERMS")
+
+	// Act
+	var s S
+	err := minienv.Load(&s, minienv.WithBitFlags(map[string]int{"read": 1}))
+
+	// Assert
+	assert.Error(t, err)
+
+	flagErr := err.(minienv.LoadError)
+	assert.Equal(t, "Perms", flagErr.Field)
+	assert.ErrorContains(t, flagErr, "unknown flag: execute")
+}
+
+func TestWithTagSeparator(t *testing.T) {
+	// Arrange
+	type S struct {
+		Hosts   []string `env:"HOSTS;split=,"`
+		Default string   `env:"DEFAULT;optional;default=a,b"`
+	}
+
+	os.Setenv("HOSTS", "a.com,b.com")
+	defer os.Unsetenv("HOSTS")
+
+	// Act
+	var s S
+	err := minienv.Load(&s, minienv.WithTagSeparator(';'))
+
+	// Assert
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"a.com", "b.com"}, s.Hosts)
+	assert.Equal(t, "a,b", s.Default)
+}
+
+func TestWithLowercaseKeys(t *testing.T) {
+	// Arrange
+	type S struct {
+		Value string `env:"VALUE"`
+	}
+
+	os.Setenv("app_value", "lowercase")
+	defer os.Unsetenv("app_value")
+
+	os.Setenv("APP_VALUE", "uppercase")
+	defer os.Unsetenv("APP_VALUE")
+
+	// Act
+	var s S
+	err := minienv.Load(&s, minienv.WithPrefix("APP_"), minienv.WithLowercaseKeys())
+
+	// Assert
+	assert.Nil(t, err)
+	assert.Equal(t, "lowercase", s.Value)
+}
+
+func TestWithLowercaseKeysAndFallbackValues(t *testing.T) {
+	// Arrange
+	type S struct {
+		Port int    `env:"PORT"`
+		Host string `env:"HOST"`
+	}
+
+	os.Setenv("host", "lowercase")
+	defer os.Unsetenv("host")
+
+	// Act
+	var s S
+	err := minienv.Load(&s, minienv.WithLowercaseKeys(), minienv.WithFallbackValues(map[string]string{"PORT": "80", "HOST": "fallback"}))
+
+	// Assert
+	assert.Nil(t, err)
+	assert.Equal(t, 80, s.Port)
+	assert.Equal(t, "lowercase", s.Host)
+}
+
+func TestWithNilLogger(t *testing.T) {
+	// Arrange
+	type S struct {
+		Value string `env:"TEST_VALUE,optional"`
+	}
+
+	// Act
+	var s S
+	err := minienv.Load(&s, minienv.WithLogger(nil), minienv.WithWarnOnConflict())
+
+	// Assert
+	assert.Error(t, err)
+	assert.ErrorContains(t, err, "logger must not be nil")
+}
+
+func TestWithSchema(t *testing.T) {
+	// Arrange
+	type S struct {
+		Port  int      `env:"PORT" json:"port"`
+		Level string   `env:"LEVEL" json:"level"`
+		Hosts []string `env:"HOSTS" json:"hosts"`
+	}
+
+	schema := []byte(`{
+		"type": "object",
+		"required": ["port", "level"],
+		"properties": {
+			"port": {"type": "integer", "minimum": 1024, "maximum": 65535},
+			"level": {"enum": ["debug", "info"]},
+			"hosts": {"type": "array", "items": {"type": "string", "pattern": "^[a-z.]+$"}}
+		}
+	}`)
+
+	os.Setenv("PORT", "8080")
+	defer os.Unsetenv("PORT")
+
+	os.Setenv("LEVEL", "info")
+	defer os.Unsetenv("LEVEL")
+
+	os.Setenv("HOSTS", "a.com|b.com")
+	defer os.Unsetenv("HOSTS")
+
+	// Act
+	var s S
+	err := minienv.Load(&s, minienv.WithSchema(schema))
+
+	// Assert
+	assert.Nil(t, err)
+	assert.Equal(t, 8080, s.Port)
+}
+
+func TestWithSchemaAndViolations(t *testing.T) {
+	// Arrange
+	type S struct {
+		Port  int      `env:"PORT" json:"port"`
+		Level string   `env:"LEVEL" json:"level"`
+		Hosts []string `env:"HOSTS" json:"hosts"`
+	}
+
+	schema := []byte(`{
+		"properties": {
+			"port": {"type": "integer", "minimum": 1024},
+			"level": {"enum": ["debug", "info"]},
+			"hosts": {"items": {"pattern": "^[a-z.]+$"}}
+		}
+	}`)
+
+	os.Setenv("PORT", "80")
+	defer os.Unsetenv("PORT")
+
+	os.Setenv("LEVEL", "trace")
+	defer os.Unsetenv("LEVEL")
+
+	os.Setenv("HOSTS", "a.com|B.COM")
+	defer os.Unsetenv("HOSTS")
+
+	// Act
+	var s S
+	err := minienv.Load(&s, minienv.WithSchema(schema))
+
+	// Assert
+	assert.Error(t, err)
+	assert.ErrorContains(t, err, "schema validation failed")
+	assert.ErrorContains(t, err, "$.port: 80 is less than the minimum of 1024")
+	assert.ErrorContains(t, err, "$.level: value trace is not one of [debug info]")
+	assert.ErrorContains(t, err, "$.hosts[1]: \"B.COM\" does not match pattern \"^[a-z.]+$\"")
+}
+
+func TestWithSchemaAndSensitiveField(t *testing.T) {
+	// Arrange
+	type Credentials struct {
+		Keys []string `env:"KEYS,sensitive" json:"keys"`
+	}
+
+	type S struct {
+		Token       string      `env:"TOKEN,sensitive" json:"token"`
+		Level       string      `env:"LEVEL,sensitive" json:"level"`
+		Credentials Credentials `json:"credentials"`
+	}
+
+	schema := []byte(`{
+		"properties": {
+			"token": {"pattern": "^[0-9]+$"},
+			"level": {"enum": ["debug", "info"]},
+			"credentials": {"properties": {"keys": {"items": {"pattern": "^k-"}}}}
+		}
+	}`)
+
+	os.Setenv("TOKEN", "supersecretvalue")
+	defer os.Unsetenv("TOKEN")
+
+	os.Setenv("LEVEL", "hidden")
+	defer os.Unsetenv("LEVEL")
+
+	os.Setenv("KEYS", "k-1|leaked")
+	defer os.Unsetenv("KEYS")
+
+	// Act
+	var s S
+	err := minienv.Load(&s, minienv.WithSchema(schema))
+
+	// Assert
+	assert.Error(t, err)
+	assert.ErrorContains(t, err, "$.token: \"***\" does not match pattern \"^[0-9]+$\"")
+	assert.ErrorContains(t, err, "$.level: value *** is not one of [debug info]")
+	assert.ErrorContains(t, err, "$.credentials.keys[1]: \"***\" does not match pattern \"^k-\"")
+	assert.NotContains(t, err.Error(), "supersecretvalue")
+	assert.NotContains(t, err.Error(), "hidden")
+	assert.NotContains(t, err.Error(), "leaked")
+}
+
+func TestWithSchemaAndInvalidSchema(t *testing.T) {
+	// Arrange
+	type S struct {
+		Port int `env:"PORT"`
+	}
+
+	// Act
+	var s S
+	err := minienv.Load(&s, minienv.WithSchema([]byte("{")))
+
+	// Assert
+	assert.Error(t, err)
+	assert.ErrorContains(t, err, "invalid schema")
+}
+
+type GobSettings struct {
+	Name    string
+	Retries int
+}
+
+func TestWithCodec(t *testing.T) {
+	// Arrange
+	type S struct {
+		Settings GobSettings `env:"SETTINGS,chain=base64"`
+	}
+
+	var buf bytes.Buffer
+	err := gob.NewEncoder(&buf).Encode(GobSettings{Name: "primary", Retries: 3})
+	assert.Nil(t, err)
+
+	os.Setenv("SETTINGS", base64.StdEncoding.EncodeToString(buf.Bytes()))
+	defer os.Unsetenv("SETTINGS")
+
+	codec := func(data []byte, field reflect.Value) error {
+		return gob.NewDecoder(bytes.NewReader(data)).DecodeValue(field)
+	}
+
+	// Act
+	var s S
+	err = minienv.Load(&s, minienv.WithCodec(reflect.TypeOf(GobSettings{}), codec))
+
+	// Assert
+	assert.Nil(t, err)
+	assert.Equal(t, GobSettings{Name: "primary", Retries: 3}, s.Settings)
+}
+
+func TestWithCodecAndError(t *testing.T) {
+	// Arrange
+	type S struct {
+		Settings GobSettings `env:"SETTINGS"`
+	}
+
+	os.Setenv("SETTINGS", "not gob")
+	defer os.Unsetenv("SETTINGS")
+
+	codecErr := errors.New("invalid settings")
+	codec := func(data []byte, field reflect.Value) error {
+		return codecErr
+	}
+
+	// Act
+	var s S
+	err := minienv.Load(&s, minienv.WithCodec(reflect.TypeOf(GobSettings{}), codec))
+
+	// Assert
+	assert.Error(t, err)
+	assert.ErrorIs(t, err, codecErr)
+
+	loadErr := err.(minienv.LoadError)
+	assert.Equal(t, "Settings", loadErr.Field)
+}
+
+func TestWithProfile(t *testing.T) {
+	// Arrange
+	type S struct {
+		Host    string `env:"DB_HOST" env_prod:"PROD_DB_HOST,default=db.internal"`
+		Port    int    `env:"DB_PORT,default=5432" env_staging:"STAGING_DB_PORT"`
+		Replica string `env_prod:"REPLICA_HOST,default=replica.internal"`
+	}
+
+	os.Setenv("DB_HOST", "localhost")
+	defer os.Unsetenv("DB_HOST")
+
+	// Act
+	var prod S
+	prodErr := minienv.Load(&prod, minienv.WithProfile("prod"))
+
+	var dev S
+	devErr := minienv.Load(&dev)
+
+	// Assert
+	assert.Nil(t, prodErr)
+	assert.Equal(t, S{Host: "db.internal", Port: 5432, Replica: "replica.internal"}, prod)
+
+	assert.Nil(t, devErr)
+	assert.Equal(t, S{Host: "localhost", Port: 5432}, dev)
+}
+
+func TestWithOptionalByDefault(t *testing.T) {
+	// Arrange
+	type S struct {
+		Optional string `env:"TEST_OPTIONAL"`
+		Number   int    `env:"TEST_NUMBER"`
+		Flag     bool   `env:"TEST_FLAG"`
+		Default  string `env:"TEST_DEFAULT,default=fallback"`
+		Required string `env:"TEST_REQUIRED,required"`
+	}
+
+	os.Setenv("TEST_REQUIRED", "value")
+	defer os.Unsetenv("TEST_REQUIRED")
+
+	// Act
+	var s S
+	err := minienv.Load(&s, minienv.WithOptionalByDefault())
+
+	// Assert
+	assert.Nil(t, err)
+	assert.Equal(t, "", s.Optional)
+	assert.Equal(t, 0, s.Number)
+	assert.False(t, s.Flag)
+	assert.Equal(t, "fallback", s.Default)
+	assert.Equal(t, "value", s.Required)
+}
+
+func TestWithOptionalByDefaultAndMissingRequired(t *testing.T) {
+	// Arrange
+	type S struct {
+		Optional string `env:"TEST_OPTIONAL"`
+		Required string `env:"TEST_REQUIRED,required"`
+	}
+
+	// Act
+	var s S
+	err := minienv.Load(&s, minienv.WithOptionalByDefault())
+
+	// Assert
+	assert.Error(t, err)
+
+	loadErr := err.(minienv.LoadError)
+	assert.Equal(t, "Required", loadErr.Field)
+	assert.ErrorContains(t, loadErr, "required field has no value and no default")
+}
+
+func TestWithValidator(t *testing.T) {
+	// Arrange
+	type S struct {
+		Min int `env:"TEST_MIN"`
+		Max int `env:"TEST_MAX"`
+	}
+
+	os.Setenv("TEST_MIN", "5")
+	defer os.Unsetenv("TEST_MIN")
+
+	os.Setenv("TEST_MAX", "2")
+	defer os.Unsetenv("TEST_MAX")
+
+	validate := func(v any) error {
+		s := v.(*S)
+		if s.Min > s.Max {
+			return errors.New("min must not be greater than max")
+		}
+
+		return nil
+	}
+
+	// Act
+	var s S
+	err := minienv.Load(&s, minienv.WithValidator(validate))
+
+	// Assert
+	assert.Error(t, err)
+	assert.EqualError(t, err, "min must not be greater than max")
+	assert.Equal(t, 5, s.Min)
+}
+
+func TestWithValidatorAndValidStruct(t *testing.T) {
+	// Arrange
+	type S struct {
+		Value string `env:"TEST_VALUE"`
+	}
+
+	os.Setenv("TEST_VALUE", "value")
+	defer os.Unsetenv("TEST_VALUE")
+
+	var validated []string
+	first := func(v any) error {
+		validated = append(validated, "first:"+v.(*S).Value)
+		return nil
+	}
+	second := func(v any) error {
+		validated = append(validated, "second:"+v.(*S).Value)
+		return nil
+	}
+
+	// Act
+	var s S
+	err := minienv.Load(&s, minienv.WithValidator(first), minienv.WithValidator(second))
+
+	// Assert
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"first:value", "second:value"}, validated)
+}
+
+func TestWithValidatorAndFailedLoad(t *testing.T) {
+	// Arrange
+	type S struct {
+		Value string `env:"TEST_VALUE"`
+	}
+
+	called := false
+	validate := func(v any) error {
+		called = true
+		return nil
+	}
+
+	// Act
+	var s S
+	err := minienv.Load(&s, minienv.WithValidator(validate))
+
+	// Assert
+	assert.Error(t, err)
+	assert.False(t, called)
+}
+
+func TestWithNilValidator(t *testing.T) {
+	// Arrange
+	type S struct {
+		Value string `env:"TEST_VALUE,optional"`
+	}
+
+	// Act
+	var s S
+	err := minienv.Load(&s, minienv.WithValidator(nil))
+
+	// Assert
+	assert.Error(t, err)
+	assert.ErrorContains(t, err, "validator must not be nil")
+}
+
+func TestWithOverrides(t *testing.T) {
+	// Arrange
+	type S struct {
+		Env      string `env:"TEST_ENV"`
+		Fallback string `env:"TEST_FALLBACK"`
+		Sources  string `env:"TEST_SOURCES,sources=default,default=default"`
+		Kept     string `env:"TEST_KEPT"`
+	}
+
+	os.Setenv("TEST_ENV", "env")
+	defer os.Unsetenv("TEST_ENV")
+
+	os.Setenv("TEST_KEPT", "env")
+	defer os.Unsetenv("TEST_KEPT")
+
+	overrides := map[string]string{
+		"TEST_ENV":      "override",
+		"TEST_FALLBACK": "override",
+		"TEST_SOURCES":  "override",
+	}
+
+	// Act
+	var s S
+	entries, err := minienv.LoadWithAudit(&s,
+		minienv.WithFallbackValues(map[string]string{"TEST_FALLBACK": "fallback"}),
+		minienv.WithOverrides(overrides),
+	)
+
+	// Assert
+	assert.Nil(t, err)
+	assert.Equal(t, "override", s.Env)
+	assert.Equal(t, "override", s.Fallback)
+	assert.Equal(t, "override", s.Sources)
+	assert.Equal(t, "env", s.Kept)
+	assert.Equal(t, minienv.SourceOverride, entries[0].Source)
+	assert.Equal(t, minienv.SourceEnv, entries[3].Source)
+}
+
+func TestWithOverridesAndPrefix(t *testing.T) {
+	// Arrange
+	type S struct {
+		Value string `env:"VALUE"`
+	}
+
+	os.Setenv("APP_VALUE", "env")
+	defer os.Unsetenv("APP_VALUE")
+
+	// Act
+	var s S
+	err := minienv.Load(&s, minienv.WithPrefix("APP_"), minienv.WithOverrides(map[string]string{"APP_VALUE": "override"}))
+
+	// Assert
+	assert.Nil(t, err)
+	assert.Equal(t, "override", s.Value)
+}
+
+func TestWithOverridesAndIndexedSlice(t *testing.T) {
+	// Arrange
+	type Server struct {
+		Host string `env:"HOST"`
+	}
+
+	type S struct {
+		Servers []Server `env:"SERVERS"`
+	}
+
+	// Act
+	var s S
+	err := minienv.Load(&s, minienv.WithOverrides(map[string]string{"SERVERS_0_HOST": "x"}))
+
+	// Assert
+	assert.Nil(t, err)
+	assert.Equal(t, []Server{{Host: "x"}}, s.Servers)
+}
+
+func TestWithOverridesAndAtomicStruct(t *testing.T) {
+	// Arrange
+	type TLS struct {
+		Cert string `env:"TLS_CERT"`
+		Key  string `env:"TLS_KEY"`
+	}
+
+	type S struct {
+		TLS TLS `env:",atomic"`
+	}
+
+	overrides := map[string]string{
+		"TLS_CERT": "cert",
+		"TLS_KEY":  "key",
+	}
+
+	// Act
+	var s S
+	err := minienv.Load(&s, minienv.WithOverrides(overrides))
+
+	// Assert
+	assert.Nil(t, err)
+	assert.Equal(t, TLS{Cert: "cert", Key: "key"}, s.TLS)
+}
+
+func TestWithOverridesAndJSONBlob(t *testing.T) {
+	// Arrange
+	type S struct {
+		Host string `env:"HOST"`
+		Port int    `env:"PORT"`
+	}
+
+	os.Setenv("APP_CONFIG", `{"Host": "env", "Port": 1}`)
+	defer os.Unsetenv("APP_CONFIG")
+
+	// Act
+	var s S
+	err := minienv.Load(&s,
+		minienv.WithJSONBlob("APP_CONFIG"),
+		minienv.WithOverrides(map[string]string{"APP_CONFIG": `{"Host": "override", "Port": 2}`}),
+	)
+
+	// Assert
+	assert.Nil(t, err)
+	assert.Equal(t, "override", s.Host)
+	assert.Equal(t, 2, s.Port)
+}
+
+func TestWithEnvSource(t *testing.T) {
+	// Arrange
+	type S struct {
+		Port int    `env:"PORT"`
+		Host string `env:"HOST,default=localhost"`
+		URL  string `env:"URL"`
+	}
+
+	os.Setenv("HOST", "process")
+	defer os.Unsetenv("HOST")
+
+	env := map[string]string{"PORT": "8080", "DOMAIN": "example.com"}
+	source := func(key string) (string, bool) {
+		val, found := env[key]
+		return val, found
+	}
+
+	// Act
+	var s S
+	err := minienv.Load(&s, minienv.WithEnvSource(source), minienv.WithReader(strings.NewReader("URL=https://${DOMAIN}")))
+
+	// Assert
+	assert.Nil(t, err)
+	assert.Equal(t, 8080, s.Port)
+	assert.Equal(t, "localhost", s.Host)
+	assert.Equal(t, "https://example.com", s.URL)
+}
+
+func TestWithNilEnvSource(t *testing.T) {
+	// Arrange
+	type S struct {
+		Value string `env:"TEST_VALUE,optional"`
+	}
+
+	// Act
+	var s S
+	err := minienv.Load(&s, minienv.WithEnvSource(nil))
+
+	// Assert
+	assert.Error(t, err)
+	assert.ErrorContains(t, err, "env source must not be nil")
+}
+
+func TestWithEnvSourceAndFrozenEnv(t *testing.T) {
+	// Arrange
+	type S struct {
+		Value string `env:"TEST_VALUE,optional"`
+	}
+
+	source := func(key string) (string, bool) {
+		return "", false
+	}
+
+	// Act
+	var s S
+	err := minienv.Load(&s, minienv.WithEnvSource(source), minienv.WithFrozenEnv())
+
+	// Assert
+	assert.Error(t, err)
+	assert.ErrorContains(t, err, "WithFrozenEnv can't be combined with WithEnvSource")
+}
@@ -0,0 +1,121 @@
+package minienv
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Provider looks up the value of a key in an external source like a secret store.
+// The second return value reports if the key was found.
+type Provider func(ctx context.Context, key string) (string, bool, error)
+
+// Supply a provider that is consulted for keys that are not present in the environment.
+// Providers take precedence over env-files and fallback values and are asked in the order
+// in which they were supplied, the first provider that finds the key wins.
+func WithProvider(provider Provider) Option {
+	return func(c *LoadConfig) error {
+		c.providers = append(c.providers, provider)
+		return nil
+	}
+}
+
+// Limit the duration of a single provider lookup.
+// If a provider doesn't return in time, the load fails with an error naming the key.
+func WithProviderTimeout(timeout time.Duration) Option {
+	return func(c *LoadConfig) error {
+		c.providerTimeout = timeout
+		return nil
+	}
+}
+
+// Retry failed provider lookups up to the given number of attempts in total,
+// waiting for the backoff between them. Waiting stops early if the context of the load is cancelled.
+func WithProviderRetry(attempts int, backoff time.Duration) Option {
+	return func(c *LoadConfig) error {
+		if attempts < 1 {
+			return errors.New("provider retry needs at least one attempt")
+		}
+
+		c.providerAttempts = attempts
+		c.providerBackoff = backoff
+		return nil
+	}
+}
+
+// Asks all providers for the key and returns the value of the first one that found it.
+func lookupProviders(key string, config *LoadConfig) (string, bool, error) {
+	for _, provider := range config.providers {
+		val, found, err := retryProvider(provider, key, config)
+		if err != nil {
+			return "", false, err
+		}
+
+		if found {
+			return val, true, nil
+		}
+	}
+
+	return "", false, nil
+}
+
+// Asks a single provider for the key and retries failed lookups as configured with WithProviderRetry.
+func retryProvider(provider Provider, key string, config *LoadConfig) (string, bool, error) {
+	for attempt := 1; ; attempt++ {
+		val, found, err := lookupProvider(provider, key, config)
+		if err == nil || attempt >= config.providerAttempts || config.ctx.Err() != nil {
+			return val, found, err
+		}
+
+		select {
+		case <-time.After(config.providerBackoff):
+		case <-config.ctx.Done():
+			return "", false, config.ctx.Err()
+		}
+	}
+}
+
+// Runs a single provider lookup, respecting the configured timeout.
+func lookupProvider(provider Provider, key string, config *LoadConfig) (string, bool, error) {
+	// don't start any lookups once the load was cancelled
+	if err := config.ctx.Err(); err != nil {
+		return "", false, err
+	}
+
+	ctx := config.ctx
+	if config.providerTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, config.providerTimeout)
+		defer cancel()
+	}
+
+	type result struct {
+		val   string
+		found bool
+		err   error
+	}
+
+	// the lookup runs in the background so that a provider that ignores the context can't block us
+	done := make(chan result, 1)
+	go func() {
+		val, found, err := provider(ctx, key)
+		done <- result{val: val, found: found, err: err}
+	}()
+
+	select {
+	case r := <-done:
+		if r.err != nil {
+			return "", false, fmt.Errorf("provider lookup for \"%s\" failed: %w", reportedKey(key, config), r.err)
+		}
+
+		return r.val, r.found, nil
+
+	case <-ctx.Done():
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) && config.ctx.Err() == nil {
+			return "", false, fmt.Errorf("provider lookup for \"%s\" timed out after %s", reportedKey(key, config), config.providerTimeout)
+		}
+
+		return "", false, ctx.Err()
+	}
+}
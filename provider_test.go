@@ -0,0 +1,300 @@
+package minienv_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/yannickalex07/minienv"
+)
+
+func TestWithProvider(t *testing.T) {
+	// Arrange
+	type S struct {
+		FromProvider string `env:"FROM_PROVIDER"`
+		FromEnv      string `env:"FROM_ENV"`
+		FromFallback string `env:"FROM_FALLBACK"`
+	}
+
+	os.Setenv("FROM_ENV", "from-env")
+	defer os.Unsetenv("FROM_ENV")
+
+	provider := func(ctx context.Context, key string) (string, bool, error) {
+		if key == "FROM_PROVIDER" || key == "FROM_ENV" {
+			return "from-provider", true, nil
+		}
+
+		return "", false, nil
+	}
+
+	values := map[string]string{
+		"FROM_PROVIDER": "from-fallback",
+		"FROM_FALLBACK": "from-fallback",
+	}
+
+	// Act
+	var s S
+	err := minienv.Load(&s, minienv.WithProvider(provider), minienv.WithFallbackValues(values))
+
+	// Assert
+	assert.Nil(t, err)
+	assert.Equal(t, "from-provider", s.FromProvider)
+	assert.Equal(t, "from-env", s.FromEnv)
+	assert.Equal(t, "from-fallback", s.FromFallback)
+}
+
+func TestWithProviderAndError(t *testing.T) {
+	// Arrange
+	type S struct {
+		Value string `env:"VALUE"`
+	}
+
+	provider := func(ctx context.Context, key string) (string, bool, error) {
+		return "", false, errors.New("connection refused")
+	}
+
+	// Act
+	var s S
+	err := minienv.Load(&s, minienv.WithProvider(provider))
+
+	// Assert
+	assert.Error(t, err)
+
+	providerErr := err.(minienv.LoadError)
+	assert.Equal(t, "Value", providerErr.Field)
+	assert.ErrorContains(t, providerErr, "provider lookup for \"VALUE\" failed: connection refused")
+}
+
+func TestWithProviderTimeout(t *testing.T) {
+	// Arrange
+	type S struct {
+		Value string `env:"VALUE"`
+	}
+
+	// this provider ignores the context and hangs
+	provider := func(ctx context.Context, key string) (string, bool, error) {
+		time.Sleep(time.Second)
+		return "too-late", true, nil
+	}
+
+	// Act
+	var s S
+	err := minienv.Load(&s, minienv.WithProvider(provider), minienv.WithProviderTimeout(10*time.Millisecond))
+
+	// Assert
+	assert.Error(t, err)
+
+	timeoutErr := err.(minienv.LoadError)
+	assert.Equal(t, "Value", timeoutErr.Field)
+	assert.ErrorContains(t, timeoutErr, "provider lookup for \"VALUE\" timed out after 10ms")
+	assert.Equal(t, "", s.Value)
+}
+
+func TestWithProviderTimeoutAndFastProvider(t *testing.T) {
+	// Arrange
+	type S struct {
+		Value string `env:"VALUE"`
+	}
+
+	provider := func(ctx context.Context, key string) (string, bool, error) {
+		return "fast", true, nil
+	}
+
+	// Act
+	var s S
+	err := minienv.Load(&s, minienv.WithProvider(provider), minienv.WithProviderTimeout(time.Second))
+
+	// Assert
+	assert.Nil(t, err)
+	assert.Equal(t, "fast", s.Value)
+}
+
+func TestWithProviderAndContextValue(t *testing.T) {
+	// Arrange
+	type S struct {
+		Value string `env:"VALUE"`
+	}
+
+	type tenantKey struct{}
+	ctx := context.WithValue(context.Background(), tenantKey{}, "tenant-a")
+
+	provider := func(ctx context.Context, key string) (string, bool, error) {
+		tenant, _ := ctx.Value(tenantKey{}).(string)
+		return tenant + "-secret", true, nil
+	}
+
+	// Act
+	var s S
+	err := minienv.LoadContext(ctx, &s, minienv.WithProvider(provider))
+
+	// Assert
+	assert.Nil(t, err)
+	assert.Equal(t, "tenant-a-secret", s.Value)
+}
+
+func TestWithProviderAndCancelledContext(t *testing.T) {
+	// Arrange
+	type S struct {
+		Value string `env:"VALUE"`
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	called := false
+	provider := func(ctx context.Context, key string) (string, bool, error) {
+		called = true
+		return "value", true, nil
+	}
+
+	// Act
+	var s S
+	err := minienv.LoadContext(ctx, &s, minienv.WithProvider(provider))
+
+	// Assert
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.False(t, called)
+}
+
+func TestWithProviderAndCancellationDuringLookup(t *testing.T) {
+	// Arrange
+	type S struct {
+		Value string `env:"VALUE"`
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	provider := func(ctx context.Context, key string) (string, bool, error) {
+		cancel()
+		<-ctx.Done()
+		return "", false, ctx.Err()
+	}
+
+	// Act
+	var s S
+	err := minienv.LoadContext(ctx, &s, minienv.WithProvider(provider))
+
+	// Assert
+	assert.ErrorIs(t, err, context.Canceled)
+}
+
+func TestLoaderWithProviderAndContext(t *testing.T) {
+	// Arrange
+	type S struct {
+		Value string `env:"VALUE"`
+	}
+
+	type tenantKey struct{}
+	ctx := context.WithValue(context.Background(), tenantKey{}, "tenant-b")
+
+	provider := func(ctx context.Context, key string) (string, bool, error) {
+		tenant, _ := ctx.Value(tenantKey{}).(string)
+		return tenant, true, nil
+	}
+
+	loader, err := minienv.NewLoader(minienv.WithProvider(provider))
+	assert.Nil(t, err)
+
+	// Act
+	var s S
+	err = loader.LoadContext(ctx, &s)
+
+	// Assert
+	assert.Nil(t, err)
+	assert.Equal(t, "tenant-b", s.Value)
+}
+
+func TestWithProviderRetry(t *testing.T) {
+	// Arrange
+	type S struct {
+		Secret string `env:"SECRET"`
+	}
+
+	calls := 0
+	provider := func(ctx context.Context, key string) (string, bool, error) {
+		calls++
+		if calls < 3 {
+			return "", false, errors.New("store unavailable")
+		}
+
+		return "from-provider", true, nil
+	}
+
+	// Act
+	var s S
+	err := minienv.Load(&s, minienv.WithProvider(provider), minienv.WithProviderRetry(3, time.Millisecond))
+
+	// Assert
+	assert.Nil(t, err)
+	assert.Equal(t, "from-provider", s.Secret)
+	assert.Equal(t, 3, calls)
+}
+
+func TestWithProviderRetryAndExhaustedAttempts(t *testing.T) {
+	// Arrange
+	type S struct {
+		Secret string `env:"SECRET"`
+	}
+
+	storeErr := errors.New("store unavailable")
+
+	calls := 0
+	provider := func(ctx context.Context, key string) (string, bool, error) {
+		calls++
+		return "", false, storeErr
+	}
+
+	// Act
+	var s S
+	err := minienv.Load(&s, minienv.WithProvider(provider), minienv.WithProviderRetry(3, time.Millisecond))
+
+	// Assert
+	assert.Error(t, err)
+	assert.ErrorIs(t, err, storeErr)
+	assert.Equal(t, 3, calls)
+
+	loadErr := err.(minienv.LoadError)
+	assert.Equal(t, "Secret", loadErr.Field)
+}
+
+func TestWithProviderRetryAndCancelledContext(t *testing.T) {
+	// Arrange
+	type S struct {
+		Secret string `env:"SECRET"`
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	calls := 0
+	provider := func(ctx context.Context, key string) (string, bool, error) {
+		calls++
+		cancel()
+		return "", false, errors.New("store unavailable")
+	}
+
+	// Act
+	var s S
+	err := minienv.LoadContext(ctx, &s, minienv.WithProvider(provider), minienv.WithProviderRetry(3, time.Hour))
+
+	// Assert
+	assert.Error(t, err)
+	assert.Equal(t, 1, calls)
+}
+
+func TestWithProviderRetryAndNoAttempts(t *testing.T) {
+	// Arrange
+	type S struct {
+		Secret string `env:"SECRET,optional"`
+	}
+
+	// Act
+	var s S
+	err := minienv.Load(&s, minienv.WithProviderRetry(0, time.Second))
+
+	// Assert
+	assert.Error(t, err)
+	assert.ErrorContains(t, err, "provider retry needs at least one attempt")
+}
@@ -0,0 +1,63 @@
+package minienv
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+// The decoders registered with RegisterType, keyed by name
+var (
+	registryMu sync.RWMutex
+	registry   = make(map[string]func(string) (any, error))
+)
+
+// Register a decoder under a name that fields can reference with the `type` option,
+// so `env:"WHEN,type=mytime"` is parsed by the decoder registered as "mytime".
+// The decoded value must be assignable to the field. Registering a name again replaces the decoder.
+// Like sql.Register, it panics if the decoder is nil.
+func RegisterType(name string, decode func(string) (any, error)) {
+	if decode == nil {
+		panic("minienv: RegisterType decoder for " + name + " is nil")
+	}
+
+	registryMu.Lock()
+	defer registryMu.Unlock()
+
+	registry[name] = decode
+}
+
+// Looks up the decoder that was registered under the name.
+func lookupType(name string) (func(string) (any, error), bool) {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+
+	decode, found := registry[name]
+	return decode, found
+}
+
+// Decodes the value with the registered decoder and assigns the result to the field.
+func setRegisteredType(f reflect.Value, val string, name string) error {
+	decode, found := lookupType(name)
+	if !found {
+		return fmt.Errorf("unknown type: %s", name)
+	}
+
+	v, err := decode(val)
+	if err != nil {
+		return fmt.Errorf("failed to decode value as type %s: %w", name, err)
+	}
+
+	if v == nil {
+		f.Set(reflect.Zero(f.Type()))
+		return nil
+	}
+
+	decoded := reflect.ValueOf(v)
+	if !decoded.Type().AssignableTo(f.Type()) {
+		return fmt.Errorf("type %s decoded a %s, which is not assignable to %s", name, decoded.Type(), f.Type())
+	}
+
+	f.Set(decoded)
+	return nil
+}
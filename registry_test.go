@@ -0,0 +1,160 @@
+package minienv_test
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/yannickalex07/minienv"
+)
+
+func TestRegisterType(t *testing.T) {
+	// Arrange
+	minienv.RegisterType("unixtime", func(val string) (any, error) {
+		sec, err := strconv.ParseInt(val, 10, 64)
+		if err != nil {
+			return nil, err
+		}
+
+		return time.Unix(sec, 0).UTC(), nil
+	})
+
+	type S struct {
+		Started time.Time `env:"STARTED,type=unixtime"`
+		Expires time.Time `env:"EXPIRES"`
+	}
+
+	os.Setenv("STARTED", "1700000000")
+	defer os.Unsetenv("STARTED")
+
+	os.Setenv("EXPIRES", "2024-01-02T03:04:05Z")
+	defer os.Unsetenv("EXPIRES")
+
+	// Act
+	var s S
+	err := minienv.Load(&s)
+
+	// Assert
+	assert.Nil(t, err)
+	assert.Equal(t, time.Unix(1700000000, 0).UTC(), s.Started)
+	assert.Equal(t, time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC), s.Expires)
+}
+
+func TestRegisterTypeWithUnassignableValue(t *testing.T) {
+	// Arrange
+	minienv.RegisterType("number", func(val string) (any, error) {
+		return strconv.Atoi(val)
+	})
+
+	type S struct {
+		Value string `env:"VALUE,type=number"`
+	}
+
+	os.Setenv("VALUE", "42")
+	defer os.Unsetenv("VALUE")
+
+	// Act
+	var s S
+	err := minienv.Load(&s)
+
+	// Assert
+	assert.Error(t, err)
+
+	typeErr := err.(minienv.LoadError)
+	assert.Equal(t, "Value", typeErr.Field)
+	assert.ErrorContains(t, typeErr, "type number decoded a int, which is not assignable to string")
+}
+
+func TestRegisterTypeWithDecodeError(t *testing.T) {
+	// Arrange
+	decodeErr := errors.New("always fails")
+	minienv.RegisterType("failing", func(val string) (any, error) {
+		return nil, decodeErr
+	})
+
+	type S struct {
+		Value int `env:"VALUE,type=failing"`
+	}
+
+	os.Setenv("VALUE", "42")
+	defer os.Unsetenv("VALUE")
+
+	// Act
+	var s S
+	err := minienv.Load(&s)
+
+	// Assert
+	assert.Error(t, err)
+	assert.ErrorIs(t, err, decodeErr)
+}
+
+func TestRegisterTypeWithUnknownName(t *testing.T) {
+	// Arrange
+	type S struct {
+		Value int `env:"VALUE,type=unregistered"`
+	}
+
+	// Act
+	var s S
+	err := minienv.Load(&s)
+
+	// Assert
+	assert.Error(t, err)
+	assert.ErrorContains(t, err, "unknown type: unregistered")
+}
+
+func TestRegisterTypeWithNilDecoder(t *testing.T) {
+	// Act & Assert
+	assert.PanicsWithValue(t, "minienv: RegisterType decoder for nildecoder is nil", func() {
+		minienv.RegisterType("nildecoder", nil)
+	})
+}
+
+type Cache interface {
+	Name() string
+}
+
+type memoryCache struct{}
+
+func (memoryCache) Name() string { return "memory" }
+
+type redisCache struct{ addr string }
+
+func (c redisCache) Name() string { return "redis@" + c.addr }
+
+func TestRegisterTypeWithInterfaceDefault(t *testing.T) {
+	// Arrange
+	minienv.RegisterType("cache", func(val string) (any, error) {
+		backend, addr, _ := strings.Cut(val, ":")
+		switch backend {
+		case "memory":
+			return memoryCache{}, nil
+		case "redis":
+			return redisCache{addr: addr}, nil
+		default:
+			return nil, fmt.Errorf("unknown cache backend: %s", backend)
+		}
+	})
+
+	type S struct {
+		Default    Cache `env:"DEFAULT_CACHE,type=cache,default=memory"`
+		Overridden Cache `env:"OVERRIDDEN_CACHE,type=cache,default=memory"`
+	}
+
+	os.Setenv("OVERRIDDEN_CACHE", "redis:localhost")
+	defer os.Unsetenv("OVERRIDDEN_CACHE")
+
+	// Act
+	var s S
+	err := minienv.Load(&s)
+
+	// Assert
+	assert.Nil(t, err)
+	assert.Equal(t, "memory", s.Default.Name())
+	assert.Equal(t, "redis@localhost", s.Overridden.Name())
+}
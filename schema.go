@@ -0,0 +1,232 @@
+package minienv
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// Supply a JSON Schema that the struct is validated against after it was loaded.
+// The struct is serialized with encoding/json, so the property names follow the json tags
+// of the fields. Only a subset of the specification is supported: type, properties, required,
+// items, enum, minimum, maximum, minLength, maxLength and pattern.
+// All violations are returned together as a single error.
+func WithSchema(schema []byte) Option {
+	return func(c *LoadConfig) error {
+		var s map[string]interface{}
+		err := json.Unmarshal(schema, &s)
+		if err != nil {
+			return fmt.Errorf("invalid schema: %w", err)
+		}
+
+		c.schema = s
+		return nil
+	}
+}
+
+// Serializes the loaded struct and validates it against the schema.
+// Values of sensitive fields are masked in the reported violations.
+func validateSchema(obj interface{}, schema map[string]interface{}, config *LoadConfig) error {
+	raw, err := json.Marshal(obj)
+	if err != nil {
+		return fmt.Errorf("failed to serialize struct for schema validation: %w", err)
+	}
+
+	var doc interface{}
+	err = json.Unmarshal(raw, &doc)
+	if err != nil {
+		return fmt.Errorf("failed to serialize struct for schema validation: %w", err)
+	}
+
+	sensitive := make(map[string]bool)
+	collectSensitivePaths(reflect.TypeOf(obj).Elem(), "$", config, sensitive)
+
+	show := func(path string, v interface{}) string {
+		if isSensitivePath(path, sensitive) {
+			return config.mask(fmt.Sprint(v))
+		}
+
+		return fmt.Sprint(v)
+	}
+
+	errs := checkSchema("$", doc, schema, show)
+	if len(errs) == 0 {
+		return nil
+	}
+
+	return fmt.Errorf("schema validation failed: %w", errors.Join(errs...))
+}
+
+// Collects the JSON paths of the fields that are tagged as sensitive, like "$.db.password".
+// Elements of slices share the path of the slice, since their indices are ignored by isSensitivePath.
+func collectSensitivePaths(s reflect.Type, path string, config *LoadConfig, paths map[string]bool) {
+	for i := 0; i < s.NumField(); i++ {
+		field := s.Field(i)
+
+		name, tagged := field.Tag.Lookup("json")
+		name, _, _ = strings.Cut(name, ",")
+		if name == "-" {
+			continue
+		}
+
+		// embedded structs without a name in the tag are flattened into the object of their parent
+		if field.Anonymous && name == "" && field.Type.Kind() == reflect.Struct {
+			collectSensitivePaths(field.Type, path, config, paths)
+			continue
+		}
+
+		if !field.IsExported() {
+			continue
+		}
+
+		if !tagged || name == "" {
+			name = field.Name
+		}
+
+		t, found, err := parseTag(field, config)
+		if found && err == nil && t.sensitive {
+			paths[path+"."+name] = true
+			continue
+		}
+
+		typ := field.Type
+		for typ.Kind() == reflect.Ptr || typ.Kind() == reflect.Slice || typ.Kind() == reflect.Array {
+			typ = typ.Elem()
+		}
+
+		if typ.Kind() == reflect.Struct {
+			collectSensitivePaths(typ, path+"."+name, config, paths)
+		}
+	}
+}
+
+// Matches the indices of array elements in a JSON path
+var pathIndexRegex = regexp.MustCompile(`\[\d+\]`)
+
+// Checks whether the path or any of its parents belongs to a sensitive field.
+func isSensitivePath(path string, paths map[string]bool) bool {
+	path = pathIndexRegex.ReplaceAllString(path, "")
+	for {
+		if paths[path] {
+			return true
+		}
+
+		i := strings.LastIndex(path, ".")
+		if i < 0 {
+			return false
+		}
+
+		path = path[:i]
+	}
+}
+
+// Recursively checks a decoded JSON value against a schema and collects all violations.
+// Values are only reported the way show formats them, which masks the values of sensitive fields.
+func checkSchema(path string, v interface{}, schema map[string]interface{}, show func(path string, v interface{}) string) []error {
+	var errs []error
+
+	if typ, ok := schema["type"].(string); ok && !matchesSchemaType(v, typ) {
+		// the remaining keywords don't make sense for a value of the wrong type
+		return []error{fmt.Errorf("%s: expected type %s", path, typ)}
+	}
+
+	if enum, ok := schema["enum"].([]interface{}); ok {
+		found := slices.ContainsFunc(enum, func(e interface{}) bool {
+			return fmt.Sprint(e) == fmt.Sprint(v)
+		})
+
+		if !found {
+			errs = append(errs, fmt.Errorf("%s: value %s is not one of %v", path, show(path, v), enum))
+		}
+	}
+
+	switch val := v.(type) {
+	case float64:
+		if min, ok := schema["minimum"].(float64); ok && val < min {
+			errs = append(errs, fmt.Errorf("%s: %s is less than the minimum of %v", path, show(path, val), min))
+		}
+
+		if max, ok := schema["maximum"].(float64); ok && val > max {
+			errs = append(errs, fmt.Errorf("%s: %s is greater than the maximum of %v", path, show(path, val), max))
+		}
+	case string:
+		length := float64(len([]rune(val)))
+		if min, ok := schema["minLength"].(float64); ok && length < min {
+			errs = append(errs, fmt.Errorf("%s: length is less than the minimum of %v", path, min))
+		}
+
+		if max, ok := schema["maxLength"].(float64); ok && length > max {
+			errs = append(errs, fmt.Errorf("%s: length is greater than the maximum of %v", path, max))
+		}
+
+		if pattern, ok := schema["pattern"].(string); ok {
+			re, err := regexp.Compile(pattern)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("%s: invalid pattern \"%s\": %w", path, pattern, err))
+			} else if !re.MatchString(val) {
+				errs = append(errs, fmt.Errorf("%s: \"%s\" does not match pattern \"%s\"", path, show(path, val), pattern))
+			}
+		}
+	case []interface{}:
+		if items, ok := schema["items"].(map[string]interface{}); ok {
+			for i, item := range val {
+				errs = append(errs, checkSchema(fmt.Sprintf("%s[%d]", path, i), item, items, show)...)
+			}
+		}
+	case map[string]interface{}:
+		if required, ok := schema["required"].([]interface{}); ok {
+			for _, name := range required {
+				if _, found := val[fmt.Sprint(name)]; !found {
+					errs = append(errs, fmt.Errorf("%s: missing required property \"%v\"", path, name))
+				}
+			}
+		}
+
+		if properties, ok := schema["properties"].(map[string]interface{}); ok {
+			for name, sub := range properties {
+				prop, found := val[name]
+				subSchema, isSchema := sub.(map[string]interface{})
+				if !found || !isSchema {
+					continue
+				}
+
+				errs = append(errs, checkSchema(path+"."+name, prop, subSchema, show)...)
+			}
+		}
+	}
+
+	return errs
+}
+
+// Checks whether a decoded JSON value is of the given JSON Schema type.
+func matchesSchemaType(v interface{}, typ string) bool {
+	switch typ {
+	case "object":
+		_, ok := v.(map[string]interface{})
+		return ok
+	case "array":
+		_, ok := v.([]interface{})
+		return ok
+	case "string":
+		_, ok := v.(string)
+		return ok
+	case "number":
+		_, ok := v.(float64)
+		return ok
+	case "integer":
+		f, ok := v.(float64)
+		return ok && f == math.Trunc(f)
+	case "boolean":
+		_, ok := v.(bool)
+		return ok
+	case "null":
+		return v == nil
+	default:
+		return true
+	}
+}